@@ -7,14 +7,21 @@ import (
 	"io"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/lucasb-eyer/go-colorful"
 	"github.com/nikolaydubina/treemap"
 	"github.com/nikolaydubina/treemap/parser"
 	"github.com/nikolaydubina/treemap/render"
 )
 
 const doc string = `
-Generate treemaps from STDIN in header-less CSV.
+Generate treemaps from STDIN in CSV.
+
+Without header, columns are positional.
 
 </ delimitered path>,<size>,<heat>
 
@@ -26,6 +33,21 @@ Africa/Angola,12420476,42
 Africa/Benin,8078314,56
 ' | treemap > out.svg
 
+With header, columns are picked by name, default names are path, size and heat.
+Other columns are kept as node attributes, which are shown in tooltips of boxes.
+
+$ echo '
+file,bytes,churn,owner
+render/svg.go,2900,12,team-a
+' | treemap -header -path-col file -size-col bytes -heat-col churn > out.svg
+
+Instead of path column, hierarchy can be in one column per level.
+
+$ echo '
+continent,country,population
+Africa,Algeria,33333216
+' | treemap -header -level-cols continent,country -size-col population > out.svg
+
 Command options:
 `
 
@@ -42,6 +64,44 @@ func main() {
 		colorBorder   string
 		imputeHeat    bool
 		keepLongPaths bool
+		hasHeader     bool
+		pathColumn    string
+		sizeColumn    string
+		heatColumn    string
+		levelColumns  string
+		inputFormat   string
+		sizePolicy    string
+		categoryCol   string
+		categoryPal   string
+		paletteFile   string
+		listPalettes  bool
+		heatScale     string
+		heatMid       float64
+		heatClipLow   float64
+		heatClipHigh  float64
+		minContrast   float64
+		depthWidths   string
+		depthColors   string
+		depthShade    float64
+		cushion       bool
+		label         string
+		textOverflow  string
+		minFontSize   float64
+		fontFamily    string
+		fontMetrics   string
+		cssFile       string
+		themeName     string
+		transparent   bool
+		title         string
+		subtitle      string
+		footer        string
+		timestamp     bool
+		urlColumn     string
+		urlTemplate   string
+		highlight     string
+		highlightRe   string
+		outputFormat  string
+		pdfOutline    bool
 	)
 
 	flag.Usage = func() {
@@ -53,24 +113,118 @@ func main() {
 	flag.Float64Var(&marginBox, "margin-box", 4, "margin between boxes")
 	flag.Float64Var(&paddingBox, "padding-box", 4, "padding between box border and content")
 	flag.Float64Var(&padding, "padding", 32, "padding around root content")
-	flag.StringVar(&colorScheme, "color", "balance", "color scheme (RdBu, balance, none)")
+	flag.StringVar(&colorScheme, "color", "balance", "color scheme (RdBu or other palette, balance, category, none)")
+	flag.StringVar(&paletteFile, "palette-file", "", "file with palette for heat in CSV of <hex color>,<position from 0 to 1>")
+	flag.BoolVar(&listPalettes, "list-palettes", false, "list available palettes and exit")
+	flag.StringVar(&categoryCol, "category-col", "", "name of column with category for category color scheme (default top-level node)")
+	flag.StringVar(&categoryPal, "category-palette", "Tableau10", "palette for category color scheme ("+strings.Join(render.CategoricalPaletteNames(), ", ")+")")
+	flag.Float64Var(&minContrast, "min-text-contrast", 0, "minimum WCAG contrast ratio of text with box, text with lower contrast gets outline (e.g. 4.5, 7)")
+	flag.StringVar(&depthWidths, "depth-border-widths", "", "comma separated border widths by depth from root, last one is for deeper (e.g. 3,2,1)")
+	flag.StringVar(&depthColors, "depth-border-colors", "", "comma separated hex border colors by depth from root, last one is for deeper (e.g. #333333,#999999)")
+	flag.Float64Var(&depthShade, "depth-shade", 0, "fraction by which each level of boxes is darker (e.g. 0.1)")
+	flag.BoolVar(&cushion, "cushion", false, "shade boxes as cushions")
+	flag.StringVar(&label, "label", render.DefaultLabelTemplate, `template of label in box, fields are name, path, size, heat, share with optional format bytes, si, % (e.g. "{name}\n{size:bytes} ({share:%})")`)
+	flag.StringVar(&textOverflow, "text-overflow", "scale", "what to do with label lines wider than box (scale, wrap, ellipsis)")
+	flag.Float64Var(&minFontSize, "min-font-size", 0, "labels that would be smaller than this font size in px are not shown")
+	flag.StringVar(&fontFamily, "font-family", render.DefaultFontFamily, "font family of text in SVG, labels fit boxes if font metrics are of this font")
+	flag.StringVar(&fontMetrics, "font-metrics", "helvetica", "glyph widths to fit labels (helvetica, estimate for fonts without glyph widths)")
+	flag.StringVar(&cssFile, "css-file", "", "file with CSS added to SVG, boxes have classes box, depth-<n>, leaf, internal, category-<category>, text has class label")
+	flag.StringVar(&themeName, "theme", "light", "background of treemap, text and borders are readable on it (light, dark)")
+	flag.BoolVar(&transparent, "transparent", false, "no background, treemap is placed on page with background of theme")
+	flag.StringVar(&title, "title", "", "title above treemap")
+	flag.StringVar(&subtitle, "subtitle", "", "subtitle below title")
+	flag.StringVar(&footer, "footer", "", "footer below treemap (e.g. source of data)")
+	flag.BoolVar(&timestamp, "timestamp", false, "add current time to footer")
+	flag.StringVar(&urlColumn, "url-col", "", "name of column in header with URL of node, boxes are links")
+	flag.StringVar(&urlTemplate, "url", "", "template of URL of node with fields {path} and {name}, boxes are links (e.g. https://github.com/org/repo/tree/main/{path})")
+	flag.StringVar(&highlight, "highlight", "", "glob of paths of nodes to highlight, other nodes are desaturated (e.g. **/*_test.go)")
+	flag.StringVar(&highlightRe, "highlight-regex", "", "regular expression of paths of nodes to highlight, other nodes are desaturated")
+	flag.StringVar(&outputFormat, "output", "svg", "format of output (svg, pdf), or tree after collapse and imputation as graph (dot, mermaid)")
+	flag.BoolVar(&pdfOutline, "pdf-outline", false, "add bookmarks for top-level nodes to PDF")
 	flag.StringVar(&colorBorder, "color-border", "auto", "color of borders (light, dark, auto)")
+	flag.StringVar(&heatScale, "heat-scale", "linear", "how to map heat to colors (linear, diverging, log, symlog, quantile, clip)")
+	flag.Float64Var(&heatMid, "heat-mid", 0, "heat that is mapped to neutral color in diverging and symlog heat scale")
+	flag.Float64Var(&heatClipLow, "heat-clip-low", 0.05, "lower percentile as fraction for clip heat scale")
+	flag.Float64Var(&heatClipHigh, "heat-clip-high", 0.95, "upper percentile as fraction for clip heat scale")
 	flag.BoolVar(&imputeHeat, "impute-heat", false, "impute heat for parents(weighted sum) and leafs(0.5)")
 	flag.BoolVar(&keepLongPaths, "long-paths", false, "keep long paths when paren has single child")
+	flag.StringVar(&inputFormat, "input", "csv", "format of input (csv, edges)")
+	flag.BoolVar(&hasHeader, "header", false, "first row of CSV is header with column names")
+	flag.StringVar(&pathColumn, "path-col", "", "name of path column in header (default path or first column that is not size or heat)")
+	flag.StringVar(&sizeColumn, "size-col", "", "name of size column in header (default size)")
+	flag.StringVar(&heatColumn, "heat-col", "", "name of heat column in header (default heat)")
+	flag.StringVar(&sizePolicy, "size-policy", "clamp", "what to do with negative, NaN and Inf sizes (clamp to zero, reject, abs)")
+	flag.StringVar(&levelColumns, "level-cols", "", "comma separated names of columns for each level of hierarchy in header, instead of path column")
 	flag.Parse()
 
+	if listPalettes {
+		fmt.Println("heat palettes:", strings.Join(render.PaletteNames(), ", "))
+		fmt.Println("category palettes:", strings.Join(render.CategoricalPaletteNames(), ", "))
+		return
+	}
+
+	switch outputFormat {
+	case "svg", "pdf", "dot", "mermaid":
+	default:
+		log.Fatalf("unknown output format: %s", outputFormat)
+	}
+
 	in, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	parser := parser.CSVTreeParser{}
-	tree, err := parser.ParseString(string(in))
-	if err != nil || tree == nil {
+	labelTemplate, err := render.ParseLabelTemplate(label)
+	if err != nil {
+		log.Fatalf("label: %s", err)
+	}
+
+	overflow, err := render.ParseTextOverflow(textOverflow)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	policy, err := treemap.ParseSizePolicy(sizePolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if policy == treemap.KeepInvalidSize {
+		// tree is validated after parsing, so invalid sizes that are kept always fail
+		log.Fatalf("size policy(%s) is not supported in command", sizePolicy)
+	}
+
+	var tree *treemap.Tree
+	switch inputFormat {
+	case "csv":
+		csvParser := parser.CSVTreeParser{
+			HasHeader:  hasHeader || pathColumn != "" || sizeColumn != "" || heatColumn != "" || levelColumns != "",
+			PathColumn: pathColumn,
+			SizeColumn: sizeColumn,
+			HeatColumn: heatColumn,
+			SizePolicy: policy,
+		}
+		if levelColumns != "" {
+			csvParser.LevelColumns = strings.Split(levelColumns, ",")
+		}
+		tree, err = csvParser.ParseString(string(in))
+		if err != nil || tree == nil {
+			log.Fatal(err)
+		}
+		treemap.SetNamesFromPaths(tree)
+	case "edges":
+		edgesParser := parser.EdgeListTreeParser{HasHeader: hasHeader, SizePolicy: policy}
+		tree, err = edgesParser.ParseString(string(in))
+		if err != nil || tree == nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown input format: %s", inputFormat)
+	}
+
+	if err := tree.Validate(); err != nil {
 		log.Fatal(err)
 	}
 
-	treemap.SetNamesFromPaths(tree)
 	if !keepLongPaths {
 		treemap.CollapseLongPaths(tree)
 	}
@@ -83,23 +237,55 @@ func main() {
 		heatImputer.ImputeHeat(*tree)
 	}
 
-	tree.NormalizeHeat()
+	var heatNormalizer treemap.HeatNormalizer
+	switch heatScale {
+	case "linear":
+		heatNormalizer = treemap.LinearHeatNormalizer{}
+	case "diverging":
+		heatNormalizer = treemap.DivergingHeatNormalizer{Mid: heatMid}
+	case "log":
+		heatNormalizer = treemap.LogHeatNormalizer{}
+	case "symlog":
+		heatNormalizer = treemap.SymLogHeatNormalizer{Mid: heatMid}
+	case "quantile":
+		heatNormalizer = treemap.QuantileHeatNormalizer{}
+	case "clip":
+		if heatClipLow < 0 || heatClipHigh > 1 || heatClipLow >= heatClipHigh {
+			log.Fatalf("heat clip percentiles(%v, %v) have to be from 0 to 1 and low below high", heatClipLow, heatClipHigh)
+		}
+		heatNormalizer = treemap.ClipHeatNormalizer{Low: heatClipLow, High: heatClipHigh}
+	default:
+		log.Fatalf("unknown heat scale: %s", heatScale)
+	}
+	heatNormalizer.NormalizeHeat(*tree)
 
 	var colorer render.Colorer
 
 	palette, hasPalette := render.GetPalette(colorScheme)
-	treeHueColorer := render.TreeHueColorer{
-		Offset: 0,
-		Hues:   map[string]float64{},
-		C:      0.5,
-		L:      0.5,
-		DeltaH: 10,
-		DeltaC: 0.3,
-		DeltaL: 0.1,
+	if paletteFile != "" {
+		paletteCSV, err := os.ReadFile(paletteFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if palette, err = render.ParsePaletteCSV(string(paletteCSV)); err != nil {
+			log.Fatalf("palette file(%s): %s", paletteFile, err)
+		}
+		hasPalette = true
 	}
+	treeHueColorer := render.NewFastTreeHueColorer(*tree, 0, 0.5, 0.5)
 
+	theme := render.Theme{FontFamily: fontFamily, Transparent: transparent}
+	switch themeName {
+	case "light":
+	case "dark":
+		theme.Dark = true
+	default:
+		log.Fatalf("unknown theme: %s", themeName)
+	}
+
+	// borders of colored boxes are gaps of background color
 	var borderColor color.Color
-	borderColor = color.White
+	borderColor = theme.Background()
 
 	switch {
 	case colorScheme == "none":
@@ -107,11 +293,18 @@ func main() {
 		borderColor = grey
 	case colorScheme == "balanced":
 		colorer = treeHueColorer
-		borderColor = color.White
+		borderColor = theme.Background()
+	case colorScheme == "category":
+		categoricalPalette, ok := render.GetCategoricalPalette(categoryPal)
+		if !ok {
+			log.Fatalf("unknown category palette: %s", categoryPal)
+		}
+		colorer = render.NewCategoricalColorer(*tree, categoricalPalette, categoryCol)
+		borderColor = theme.Background()
 	case hasPalette && tree.HasHeat():
 		colorer = render.HeatColorer{Palette: palette}
 		if imputeHeat {
-			borderColor = color.White
+			borderColor = theme.Background()
 		} else {
 			borderColor = grey
 		}
@@ -119,7 +312,7 @@ func main() {
 		palette, _ := render.GetPalette("RdBu")
 		colorer = render.HeatColorer{Palette: palette}
 		if imputeHeat {
-			borderColor = color.White
+			borderColor = theme.Background()
 		} else {
 			borderColor = grey
 		}
@@ -134,12 +327,110 @@ func main() {
 		borderColor = grey
 	}
 
+	if highlight != "" || highlightRe != "" {
+		var pattern *regexp.Regexp
+		var err error
+		switch {
+		case highlight != "" && highlightRe != "":
+			log.Fatal("only one of highlight glob and highlight regex can be set")
+		case highlight != "":
+			pattern, err = render.CompileGlob(highlight)
+		default:
+			pattern, err = regexp.Compile(highlightRe)
+		}
+		if err != nil {
+			log.Fatalf("highlight: %s", err)
+		}
+		colorer = render.HighlightColorer{Colorer: colorer, Pattern: pattern}
+	}
+
+	// graphs are of tree itself, they do not need layout
+	switch outputFormat {
+	case "dot":
+		renderer := render.DOTRenderer{Colorer: colorer}
+		if err := renderer.RenderTo(os.Stdout, *tree); err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "mermaid":
+		renderer := render.MermaidRenderer{}
+		if err := renderer.RenderTo(os.Stdout, *tree); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	depthStyle := render.DepthStyle{Shade: depthShade}
+	if depthWidths != "" {
+		for _, v := range strings.Split(depthWidths, ",") {
+			width, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				log.Fatalf("border width(%s): %s", v, err)
+			}
+			depthStyle.BorderWidths = append(depthStyle.BorderWidths, width)
+		}
+	}
+	if depthColors != "" {
+		for _, v := range strings.Split(depthColors, ",") {
+			c, err := colorful.Hex(strings.TrimSpace(v))
+			if err != nil {
+				log.Fatalf("border color(%s): %s", v, err)
+			}
+			depthStyle.BorderColors = append(depthStyle.BorderColors, c)
+		}
+	}
+
 	uiBuilder := render.UITreeMapBuilder{
-		Colorer:     colorer,
-		BorderColor: borderColor,
+		Colorer:         colorer,
+		BorderColor:     borderColor,
+		MinTextContrast: minContrast,
+		DepthStyle:      depthStyle,
+		Cushion:         cushion,
+		Label:           labelTemplate,
+		TextOverflow:    overflow,
+		MinFontSize:     minFontSize,
+		Background:      theme.Background(),
+		Title:           title,
+		Subtitle:        subtitle,
+		Footer:          footer,
+	}
+	if timestamp {
+		uiBuilder.Timestamp = time.Now()
+	}
+	switch {
+	case urlColumn != "" && urlTemplate != "":
+		log.Fatal("only one of url column and url template can be set")
+	case urlColumn != "":
+		uiBuilder.Linker = render.AttributeLinker{Attribute: urlColumn}
+	case urlTemplate != "":
+		uiBuilder.Linker = render.TemplateLinker{Template: urlTemplate}
+	}
+	switch fontMetrics {
+	case "helvetica":
+		uiBuilder.TextMeasurer = render.HelveticaTextMeasurer{}
+	case "estimate":
+		uiBuilder.TextMeasurer = render.RuneCountTextMeasurer{}
+	default:
+		log.Fatalf("unknown font metrics: %s", fontMetrics)
 	}
 	spec := uiBuilder.NewUITreeMap(*tree, w, h, marginBox, paddingBox, padding)
-	renderer := render.SVGRenderer{}
-
-	os.Stdout.Write(renderer.Render(spec, w, h))
+	switch outputFormat {
+	case "svg":
+		renderer := render.SVGRenderer{Theme: theme}
+		if cssFile != "" {
+			css, err := os.ReadFile(cssFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			renderer.Theme.CSS = string(css)
+		}
+		if err := renderer.RenderTo(os.Stdout, spec, w, h); err != nil {
+			log.Fatal(err)
+		}
+	case "pdf":
+		renderer := render.PDFRenderer{Theme: theme, Outline: pdfOutline}
+		if err := renderer.RenderTo(os.Stdout, spec, w, h); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
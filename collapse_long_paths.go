@@ -7,18 +7,38 @@ func CollapseLongPaths(t *Tree) {
 	if t == nil {
 		return
 	}
-	CollapseLongPathsFromNode(t, t.Root)
+	t.Root = collapseLongPathsFromNode(t, t.Root)
 }
 
 // CollapseLongPathsFromNode will collapse current node into children as long as it has single child.
-// Will set name of this node to joined path from roots.
-// Will set size and heat to this child's size and heat.
+// Will set name of last child in chain to joined path from current node.
+// Last child in chain takes place of current node, edges to current node and root are updated.
 // Expecting Name containing either single value for current node.
 func CollapseLongPathsFromNode(t *Tree, nodeName string) {
 	if t == nil {
 		return
 	}
 
+	q := collapseLongPathsFromNode(t, nodeName)
+	if q == nodeName {
+		return
+	}
+
+	if t.Root == nodeName {
+		t.Root = q
+	}
+	for _, children := range t.To {
+		for i, child := range children {
+			if child == nodeName {
+				children[i] = q
+			}
+		}
+	}
+}
+
+// collapseLongPathsFromNode returns identifier of node that took place of current node.
+// Caller should update edges to current node.
+func collapseLongPathsFromNode(t *Tree, nodeName string) string {
 	parts := []string{}
 	q := nodeName
 	for children := t.To[q]; len(children) == 1; children = t.To[q] {
@@ -33,31 +53,19 @@ func CollapseLongPathsFromNode(t *Tree, nodeName string) {
 
 	// if we deleted some children
 	if q != nodeName {
-		// redirect edges from current node to last child
-		t.To[nodeName] = make([]string, len(t.To[q]))
-		copy(t.To[nodeName], t.To[q])
-
 		node := t.Nodes[q]
 
 		// add last child node name to path
 		parts = append(parts, node.Name)
 
-		// copy fields from child to current node
-		t.Nodes[nodeName] = Node{
-			Path:    node.Path,
-			Name:    strings.Join(parts, "/"),
-			Size:    node.Size,
-			Heat:    node.Heat,
-			HasHeat: node.HasHeat,
-		}
-
-		// delete last child, since it is unreachable now
-		delete(t.Nodes, q)
-		delete(t.To, q)
+		node.Name = strings.Join(parts, "/")
+		t.Nodes[q] = node
 	}
 
 	// recursively collapse
-	for _, node := range t.To[nodeName] {
-		CollapseLongPathsFromNode(t, node)
+	for i, child := range t.To[q] {
+		t.To[q][i] = collapseLongPathsFromNode(t, child)
 	}
+
+	return q
 }
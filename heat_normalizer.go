@@ -0,0 +1,162 @@
+package treemap
+
+import (
+	"math"
+	"sort"
+)
+
+// HeatNormalizer maps heat of nodes into [0, 1], which is range of palettes.
+// Heat before normalization is kept as raw heat, if nodes do not have it yet.
+type HeatNormalizer interface {
+	NormalizeHeat(t Tree)
+}
+
+// LinearHeatNormalizer maps min heat to 0 and max heat to 1.
+type LinearHeatNormalizer struct{}
+
+func (s LinearHeatNormalizer) NormalizeHeat(t Tree) { t.NormalizeHeat() }
+
+// DivergingHeatNormalizer maps Mid to 0.5, which is neutral color of diverging palettes.
+// Heat that is furthest from Mid is mapped to 0 or 1, other side is scaled with same factor.
+type DivergingHeatNormalizer struct {
+	Mid float64
+}
+
+func (s DivergingHeatNormalizer) NormalizeHeat(t Tree) {
+	keepRawHeat(t)
+	s.normalizeHeat(t)
+}
+
+func (s DivergingHeatNormalizer) normalizeHeat(t Tree) {
+	minHeat, maxHeat := t.HeatRange()
+	d := math.Max(math.Abs(maxHeat-s.Mid), math.Abs(minHeat-s.Mid))
+	mapHeat(t, func(h float64) float64 {
+		if d < minHeatDifferenceForHeatmap {
+			return 0.5
+		}
+		return 0.5 + ((h - s.Mid) / (2 * d))
+	})
+}
+
+// LogHeatNormalizer maps logarithm of heat linearly.
+// Heat that is not positive is set to smallest positive heat.
+type LogHeatNormalizer struct{}
+
+func (s LogHeatNormalizer) NormalizeHeat(t Tree) {
+	keepRawHeat(t)
+	minPositive := math.Inf(1)
+	for _, node := range t.Nodes {
+		if node.HasHeat && node.Heat > 0 && node.Heat < minPositive {
+			minPositive = node.Heat
+		}
+	}
+	if math.IsInf(minPositive, 1) {
+		return
+	}
+
+	mapHeat(t, func(h float64) float64 { return math.Log(math.Max(h, minPositive)) })
+	t.normalizeHeat()
+}
+
+// SymLogHeatNormalizer is logarithmic for large differences from Mid and linear close to Mid.
+// It keeps sign, so Mid is mapped to 0.5 same as in DivergingHeatNormalizer.
+// C is scale of linear region around Mid, default is 1.
+type SymLogHeatNormalizer struct {
+	Mid float64
+	C   float64
+}
+
+func (s SymLogHeatNormalizer) NormalizeHeat(t Tree) {
+	keepRawHeat(t)
+	c := s.C
+	if c <= 0 {
+		c = 1
+	}
+	mapHeat(t, func(h float64) float64 {
+		d := h - s.Mid
+		return math.Copysign(math.Log1p(math.Abs(d)/c), d)
+	})
+	DivergingHeatNormalizer{Mid: 0}.normalizeHeat(t)
+}
+
+// QuantileHeatNormalizer maps heat to its rank among all heats, equal heats have same average rank.
+// This makes colors evenly distributed regardless of distribution of heat.
+type QuantileHeatNormalizer struct{}
+
+func (s QuantileHeatNormalizer) NormalizeHeat(t Tree) {
+	keepRawHeat(t)
+	heats := sortedHeats(t)
+	if len(heats) < 2 {
+		return
+	}
+
+	rank := make(map[float64]float64, len(heats))
+	for i := 0; i < len(heats); {
+		j := i
+		for j < len(heats) && heats[j] == heats[i] {
+			j++
+		}
+		rank[heats[i]] = (float64(i+j-1) / 2) / float64(len(heats)-1)
+		i = j
+	}
+
+	mapHeat(t, func(h float64) float64 { return rank[h] })
+}
+
+// ClipHeatNormalizer clips heat to percentiles and maps clipped range linearly.
+// This way few outliers do not make all other nodes same color.
+// Low and High are percentiles as fractions from 0 to 1.
+// If clipped range is empty, such as when Low is not below High, all heat is mapped to 0.5.
+type ClipHeatNormalizer struct {
+	Low  float64
+	High float64
+}
+
+func (s ClipHeatNormalizer) NormalizeHeat(t Tree) {
+	keepRawHeat(t)
+	heats := sortedHeats(t)
+	if len(heats) == 0 {
+		return
+	}
+
+	low, high := percentile(heats, s.Low), percentile(heats, s.High)
+	mapHeat(t, func(h float64) float64 {
+		if (high - low) < minHeatDifferenceForHeatmap {
+			return 0.5
+		}
+		return (math.Min(math.Max(h, low), high) - low) / (high - low)
+	})
+}
+
+// mapHeat sets heat of each node that has heat.
+func mapHeat(t Tree, f func(h float64) float64) {
+	for path, node := range t.Nodes {
+		if !node.HasHeat {
+			continue
+		}
+		node.Heat = f(node.Heat)
+		t.Nodes[path] = node
+	}
+}
+
+func sortedHeats(t Tree) []float64 {
+	heats := make([]float64, 0, len(t.Nodes))
+	for _, node := range t.Nodes {
+		if node.HasHeat {
+			heats = append(heats, node.Heat)
+		}
+	}
+	sort.Float64s(heats)
+	return heats
+}
+
+// percentile of sorted values with linear interpolation between closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	p = math.Min(math.Max(p, 0), 1)
+	pos := p * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + ((pos - float64(i)) * (sorted[i+1] - sorted[i]))
+}
@@ -0,0 +1,160 @@
+package treemap
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHeatNormalizers(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer HeatNormalizer
+		heats      []float64
+		expHeats   []float64
+	}{
+		{
+			name:       "linear",
+			normalizer: LinearHeatNormalizer{},
+			heats:      []float64{-10, 0, 30},
+			expHeats:   []float64{0, 0.25, 1},
+		},
+		{
+			name:       "when diverging, then mid is neutral and other side is scaled same",
+			normalizer: DivergingHeatNormalizer{Mid: 0},
+			heats:      []float64{-10, 0, 5, 20},
+			expHeats:   []float64{0.25, 0.5, 0.625, 1},
+		},
+		{
+			name:       "when diverging and all heats at mid, then neutral",
+			normalizer: DivergingHeatNormalizer{Mid: 3},
+			heats:      []float64{3, 3},
+			expHeats:   []float64{0.5, 0.5},
+		},
+		{
+			name:       "when log, then orders of magnitude are equally spaced and not positive is min",
+			normalizer: LogHeatNormalizer{},
+			heats:      []float64{1, 10, 100, 0, -5},
+			expHeats:   []float64{0, 0.5, 1, 0, 0},
+		},
+		{
+			name:       "when symlog, then symmetric around mid",
+			normalizer: SymLogHeatNormalizer{Mid: 0, C: 1},
+			heats:      []float64{-99, 0, 9, 99},
+			expHeats:   []float64{0, 0.5, 0.75, 1},
+		},
+		{
+			name:       "when quantile, then by rank and ties have same rank",
+			normalizer: QuantileHeatNormalizer{},
+			heats:      []float64{1, 1000, 2, 2, 3},
+			expHeats:   []float64{0, 1, 0.375, 0.375, 0.75},
+		},
+		{
+			name:       "when clip, then outliers are clipped",
+			normalizer: ClipHeatNormalizer{Low: 0.25, High: 0.75},
+			heats:      []float64{-1000, 1, 2, 3, 1000},
+			expHeats:   []float64{0, 0, 0.5, 1, 1},
+		},
+		{
+			name:       "when clip and clipped range has same heats, then neutral",
+			normalizer: ClipHeatNormalizer{Low: 0.25, High: 0.75},
+			heats:      []float64{5, 5, 5, 5, 72},
+			expHeats:   []float64{0.5, 0.5, 0.5, 0.5, 0.5},
+		},
+		{
+			name:       "when clip and low is above high, then neutral",
+			normalizer: ClipHeatNormalizer{Low: 0.75, High: 0.25},
+			heats:      []float64{1, 2, 72},
+			expHeats:   []float64{0.5, 0.5, 0.5},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree := Tree{Nodes: map[string]Node{}}
+			for i, h := range tc.heats {
+				tree.Nodes[string(rune('a'+i))] = Node{Heat: h, HasHeat: true, RawHeat: h, HasRawHeat: true}
+			}
+			tree.Nodes["no-heat"] = Node{Heat: 42}
+
+			tc.normalizer.NormalizeHeat(tree)
+
+			for i, exp := range tc.expHeats {
+				node := tree.Nodes[string(rune('a'+i))]
+				if math.Abs(node.Heat-exp) > 0.0001 {
+					t.Errorf("heat(%v): exp(%v) != got(%v)", tc.heats[i], exp, node.Heat)
+				}
+				if node.RawHeat != tc.heats[i] {
+					t.Errorf("raw heat: exp(%v) != got(%v)", tc.heats[i], node.RawHeat)
+				}
+			}
+			if tree.Nodes["no-heat"].Heat != 42 {
+				t.Error("node without heat is changed")
+			}
+		})
+	}
+}
+
+func TestHeatNormalizersKeepRawHeatOfTreeMadeInCode(t *testing.T) {
+	normalizers := []HeatNormalizer{
+		LinearHeatNormalizer{},
+		DivergingHeatNormalizer{Mid: 50},
+		LogHeatNormalizer{},
+		SymLogHeatNormalizer{Mid: 50},
+		QuantileHeatNormalizer{},
+		ClipHeatNormalizer{Low: 0.1, High: 0.9},
+	}
+	for _, normalizer := range normalizers {
+		t.Run(fmt.Sprintf("%T", normalizer), func(t *testing.T) {
+			tree := Tree{
+				Nodes: map[string]Node{
+					"a":   {Path: "a", Name: "a", Size: 3},
+					"a/b": {Path: "a/b", Name: "b", Size: 1, Heat: 72, HasHeat: true},
+					"a/c": {Path: "a/c", Name: "c", Size: 1, Heat: 42, HasHeat: true},
+					"a/d": {Path: "a/d", Name: "d", Size: 1, Heat: 0, HasHeat: true},
+				},
+				To:   map[string][]string{"a": {"a/b", "a/c", "a/d"}},
+				Root: "a",
+			}
+
+			normalizer.NormalizeHeat(tree)
+
+			for node, exp := range map[string]float64{"a/b": 72, "a/c": 42, "a/d": 0} {
+				if got := tree.Nodes[node].RawHeat; got != exp {
+					t.Errorf("node(%s) raw heat: exp(%v) != got(%v)", node, exp, got)
+				}
+			}
+			if n := tree.Nodes["a/b"]; n.Heat < 0 || n.Heat > 1 {
+				t.Errorf("heat(%v) is not normalized", n.Heat)
+			}
+		})
+	}
+}
+
+func TestHeatNormalizersTwiceKeepRawHeat(t *testing.T) {
+	normalizers := []HeatNormalizer{
+		LinearHeatNormalizer{},
+		DivergingHeatNormalizer{Mid: 0},
+		LogHeatNormalizer{},
+		SymLogHeatNormalizer{Mid: 0},
+		QuantileHeatNormalizer{},
+		ClipHeatNormalizer{Low: 0.1, High: 0.9},
+	}
+	for _, normalizer := range normalizers {
+		t.Run(fmt.Sprintf("%T", normalizer), func(t *testing.T) {
+			heats := map[string]float64{"a/b": -10, "a/c": 0, "a/d": 30}
+			tree := Tree{Nodes: map[string]Node{"a": {Path: "a"}}, Root: "a"}
+			for path, h := range heats {
+				tree.Nodes[path] = Node{Path: path, Size: 1, Heat: h, HasHeat: true}
+			}
+
+			normalizer.NormalizeHeat(tree)
+			normalizer.NormalizeHeat(tree)
+
+			for path, exp := range heats {
+				if got := tree.Nodes[path].RawHeat; got != exp {
+					t.Errorf("node(%s) raw heat: exp(%v) != got(%v)", path, exp, got)
+				}
+			}
+		})
+	}
+}
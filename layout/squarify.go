@@ -24,7 +24,13 @@ type wrappedArea struct {
 // This function does sanity checks and hardening so that algorithm can work in the wild.
 // Returns boxes in same order as areas.
 // Zero areas will have zero-value box.
+// Negative, NaN and Inf areas are treated as zero.
+// If box has negative, NaN or Inf dimensions, then all boxes are zero-value.
 func Squarify(box Box, areas []float64) []Box {
+	if !isFinite(box.X) || !isFinite(box.Y) || !isFinite(box.W) || !isFinite(box.H) || box.W < 0 || box.H < 0 {
+		return make([]Box, len(areas))
+	}
+
 	// normalize and sort from highest to lowest
 	sortedAreas := make([]wrappedArea, len(areas))
 	for i, s := range normalizeAreas(areas, (box.W * box.H)) {
@@ -65,22 +71,32 @@ func Squarify(box Box, areas []float64) []Box {
 	return res
 }
 
+// normalizeAreas scales areas to add up to target.
+// Negative, NaN and Inf areas are set to zero.
+// If there is no valid area, then all areas are zero.
 func normalizeAreas(areas []float64, target float64) []float64 {
+	n := make([]float64, len(areas))
 	var total float64
-	for _, s := range areas {
-		total += s
+	for i, s := range areas {
+		if isFinite(s) && s > 0 {
+			n[i] = s
+			total += s
+		}
 	}
 	if total == target {
-		return areas
+		return n
+	}
+	if total == 0 || !isFinite(total) {
+		return make([]float64, len(areas))
 	}
-	n := make([]float64, len(areas))
-	copy(n, areas)
 	for i, s := range n {
 		n[i] = target * s / total
 	}
 	return n
 }
 
+func isFinite(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
+
 // squarifyBoxLayout defines how to partition BoundingBox into boxes
 type squarifyBoxLayout struct {
 	boxes     []Box // fixed boxes that have been positioned and sized already
@@ -2,6 +2,7 @@ package layout
 
 import (
 	"fmt"
+	"math"
 	"testing"
 )
 
@@ -302,6 +303,46 @@ func TestSquarify(t *testing.T) {
 				{X: 0, Y: 0, W: 9, H: 3},
 			},
 		},
+		{
+			name:  "when has negative, then zero value box is returned",
+			box:   Box{W: 12, H: 3},
+			areas: []float64{1, -5, 3},
+			expBoxes: []Box{
+				{X: 9, Y: 0, W: 3, H: 3},
+				{},
+				{X: 0, Y: 0, W: 9, H: 3},
+			},
+		},
+		{
+			name:  "when has NaN and Inf, then zero value box is returned",
+			box:   Box{W: 12, H: 3},
+			areas: []float64{1, math.NaN(), 3, math.Inf(1), math.Inf(-1)},
+			expBoxes: []Box{
+				{X: 9, Y: 0, W: 3, H: 3},
+				{},
+				{X: 0, Y: 0, W: 9, H: 3},
+				{},
+				{},
+			},
+		},
+		{
+			name:     "when all invalid, then all zero value boxes",
+			box:      Box{W: 12, H: 3},
+			areas:    []float64{math.NaN(), -1},
+			expBoxes: []Box{{}, {}},
+		},
+		{
+			name:     "when bounding box is NaN, then all zero value boxes",
+			box:      Box{W: math.NaN(), H: 3},
+			areas:    []float64{1, 2},
+			expBoxes: []Box{{}, {}},
+		},
+		{
+			name:     "when bounding box is negative, then all zero value boxes",
+			box:      Box{W: -12, H: 3},
+			areas:    []float64{1, 2},
+			expBoxes: []Box{{}, {}},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -314,6 +355,9 @@ func TestSquarify(t *testing.T) {
 				if (b == Box{}) {
 					continue
 				}
+				if !isFinite(b.X) || !isFinite(b.Y) || !isFinite(b.W) || !isFinite(b.H) {
+					t.Errorf("box(%d: %#v) is not finite", i, b)
+				}
 				if (b.H * b.W) < 0.1 {
 					t.Errorf("got wrong size for box(%d: %#v)", i, b)
 				}
@@ -12,17 +12,51 @@ import (
 	"github.com/nikolaydubina/treemap"
 )
 
+// Default column names when CSV has header.
+const (
+	DefaultPathColumn = "path"
+	DefaultSizeColumn = "size"
+	DefaultHeatColumn = "heat"
+)
+
+// CSVTreeParser parses CSV where each row is a node.
+// Without header, columns are positional: </ delimitered path>,<size>,<heat>.
+// With header, columns are picked by name and all other columns are kept as node attributes.
+// With level columns, path is made of one column per level of hierarchy instead of path column.
+// Empty trailing levels make row a node higher in hierarchy.
+// Slashes in level values are escaped as HTML entity, so they do not split path.
 // if duplicates, then sum size
 // if duplicates, then max heat
 // TODO: policies for duplicates
-type CSVTreeParser struct{}
+type CSVTreeParser struct {
+	HasHeader  bool   // first row is header with column names
+	PathColumn string // name of path column, first column that is not size or heat if not present in header
+	SizeColumn string // name of size column, optional
+	HeatColumn string // name of heat column, optional
+
+	LevelColumns []string // names of columns for each level of hierarchy from top, replaces path column
+
+	SizePolicy treemap.SizePolicy // what to do with negative, NaN and Inf sizes, default is to keep them
+}
 
 func (s CSVTreeParser) ParseString(in string) (*treemap.Tree, error) {
-	nodes, err := parseNodes(in)
+	var nodes []treemap.Node
+	var err error
+	if s.HasHeader {
+		nodes, err = parseNodesWithHeader(in, s.columns())
+	} else {
+		nodes, err = parseNodes(in)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("can not parse nodes: %w", err)
 	}
 
+	for i, node := range nodes {
+		if nodes[i].Size, err = s.SizePolicy.SanitizeSize(node.Size); err != nil {
+			return nil, fmt.Errorf("node(%s): %w", node.Path, err)
+		}
+	}
+
 	tree, err := makeTree(nodes)
 	if err != nil {
 		return nil, fmt.Errorf("can not make tree: %w", err)
@@ -31,6 +65,188 @@ func (s CSVTreeParser) ParseString(in string) (*treemap.Tree, error) {
 	return tree, nil
 }
 
+func (s CSVTreeParser) columns() csvColumns {
+	c := csvColumns{
+		path: DefaultPathColumn,
+		size: DefaultSizeColumn,
+		heat: DefaultHeatColumn,
+	}
+	if s.PathColumn != "" {
+		c.path, c.requirePath = s.PathColumn, true
+	}
+	if s.SizeColumn != "" {
+		c.size, c.requireSize = s.SizeColumn, true
+	}
+	if s.HeatColumn != "" {
+		c.heat, c.requireHeat = s.HeatColumn, true
+	}
+	c.levels = s.LevelColumns
+	return c
+}
+
+// csvColumns are names of columns with special meaning.
+// If column is required, then it is an error if it is missing in header.
+type csvColumns struct {
+	path        string
+	size        string
+	heat        string
+	requirePath bool
+	requireSize bool
+	requireHeat bool
+	levels      []string
+}
+
+// slashEscaper makes value safe to be single part of path
+var slashEscaper = strings.NewReplacer("/", "&sol;")
+
+// parseNodesWithHeader reads first row as column names and picks columns by name.
+// Columns that are not path, size or heat are stored as attributes.
+func parseNodesWithHeader(in string, columns csvColumns) ([]treemap.Node, error) {
+	r := csv.NewReader(strings.NewReader(in))
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not parse header: %w", err)
+	}
+
+	pathIdx, sizeIdx, heatIdx := -1, -1, -1
+	isLevel := map[int]bool{}
+	levelIdx := make([]int, len(columns.levels))
+	for i := range levelIdx {
+		levelIdx[i] = -1
+	}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		for j, level := range columns.levels {
+			if name == level {
+				levelIdx[j] = i
+				isLevel[i] = true
+			}
+		}
+		switch {
+		case isLevel[i]:
+			continue
+		case name == columns.path && len(columns.levels) == 0:
+			pathIdx = i
+		case name == columns.size:
+			sizeIdx = i
+		case name == columns.heat:
+			heatIdx = i
+		}
+	}
+
+	for j, idx := range levelIdx {
+		if idx < 0 {
+			return nil, fmt.Errorf("level column(%s) is not in header", columns.levels[j])
+		}
+	}
+
+	switch {
+	case sizeIdx < 0 && columns.requireSize:
+		return nil, fmt.Errorf("size column(%s) is not in header", columns.size)
+	case heatIdx < 0 && columns.requireHeat:
+		return nil, fmt.Errorf("heat column(%s) is not in header", columns.heat)
+	case len(columns.levels) > 0:
+		// path is made from levels
+	case pathIdx < 0 && columns.requirePath:
+		return nil, fmt.Errorf("path column(%s) is not in header", columns.path)
+	case pathIdx < 0:
+		// first column that is not size or heat
+		for i := range header {
+			if i != sizeIdx && i != heatIdx {
+				pathIdx = i
+				break
+			}
+		}
+		if pathIdx < 0 {
+			return nil, errors.New("no column for path in header, path column has to be set")
+		}
+	}
+
+	var nodes []treemap.Node
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can not parse: %w", err)
+		}
+
+		var node treemap.Node
+		if len(levelIdx) > 0 {
+			path, err := pathFromLevels(record, levelIdx)
+			if err != nil {
+				return nil, err
+			}
+			node.Path = path
+		} else {
+			node.Path = record[pathIdx]
+		}
+
+		if sizeIdx >= 0 && record[sizeIdx] != "" {
+			v, err := strconv.ParseFloat(record[sizeIdx], 64)
+			if err != nil {
+				return nil, fmt.Errorf("size(%s) is not float: %w", record[sizeIdx], err)
+			}
+			node.Size = v
+		}
+
+		if heatIdx >= 0 && record[heatIdx] != "" {
+			v, err := parseHeat(record[heatIdx])
+			if err != nil {
+				return nil, err
+			}
+			node.Heat = v
+			node.RawHeat = v
+			node.HasRawHeat = true
+			node.HasHeat = true
+		}
+
+		for i, v := range record {
+			if i == pathIdx || i == sizeIdx || i == heatIdx || isLevel[i] || v == "" {
+				continue
+			}
+			if node.Attributes == nil {
+				node.Attributes = map[string]string{}
+			}
+			node.Attributes[strings.TrimSpace(header[i])] = v
+		}
+
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// pathFromLevels joins values of level columns into path.
+// Empty levels are allowed only at the end.
+func pathFromLevels(record []string, levelIdx []int) (string, error) {
+	parts := make([]string, 0, len(levelIdx))
+	for _, idx := range levelIdx {
+		v := record[idx]
+		if v == "" {
+			break
+		}
+		parts = append(parts, slashEscaper.Replace(v))
+	}
+
+	if len(parts) == 0 {
+		return "", errors.New("all levels are empty")
+	}
+
+	for _, idx := range levelIdx[len(parts):] {
+		if record[idx] != "" {
+			return "", fmt.Errorf("level(%s) follows empty level", record[idx])
+		}
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
 func parseNodes(in string) ([]treemap.Node, error) {
 	var nodes []treemap.Node
 	r := csv.NewReader(strings.NewReader(in))
@@ -59,11 +275,13 @@ func parseNodes(in string) ([]treemap.Node, error) {
 		}
 
 		if len(record) >= 3 {
-			v, err := strconv.ParseFloat(record[2], 64)
+			v, err := parseHeat(record[2])
 			if err != nil {
-				return nil, fmt.Errorf("heat(%s) is not float: %w", record[2], err)
+				return nil, err
 			}
 			node.Heat = v
+			node.RawHeat = v
+			node.HasRawHeat = true
 			node.HasHeat = true
 		}
 
@@ -72,6 +290,18 @@ func parseNodes(in string) ([]treemap.Node, error) {
 	return nodes, nil
 }
 
+// parseHeat parses heat, which has to be finite number.
+func parseHeat(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("heat(%s) is not float: %w", s, err)
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("heat(%s) is not finite", s)
+	}
+	return v, nil
+}
+
 // If node is in path, but not present, then it will be in To but not will have entry in Nodes.
 // This is not terribly efficient, but should do its job for small graphs.
 func makeTree(nodes []treemap.Node) (*treemap.Tree, error) {
@@ -80,22 +310,28 @@ func makeTree(nodes []treemap.Node) (*treemap.Tree, error) {
 		To:    map[string][]string{},
 	}
 
-	// for finding roots
+	// for finding roots, in order of appearance so that output is same for same input
 	hasParent := map[string]bool{}
+	var candidates []string
 
 	for _, node := range nodes {
 		if existingNode, ok := tree.Nodes[node.Path]; ok {
 			tree.Nodes[node.Path] = treemap.Node{
-				Path:    existingNode.Path,
-				Name:    existingNode.Name,
-				Size:    existingNode.Size + node.Size,
-				Heat:    math.Max(existingNode.Heat, node.Heat),
-				HasHeat: existingNode.HasHeat || node.HasHeat,
+				Path:       existingNode.Path,
+				Name:       existingNode.Name,
+				Size:       existingNode.Size + node.Size,
+				Heat:       math.Max(existingNode.Heat, node.Heat),
+				HasHeat:    existingNode.HasHeat || node.HasHeat,
+				RawHeat:    math.Max(existingNode.RawHeat, node.RawHeat),
+				HasRawHeat: existingNode.HasRawHeat || node.HasRawHeat,
 			}
 		}
 		tree.Nodes[node.Path] = node
 
 		parts := strings.Split(node.Path, "/")
+		if _, ok := hasParent[parts[0]]; !ok {
+			candidates = append(candidates, parts[0])
+		}
 		hasParent[parts[0]] = false
 
 		for parent, i := parts[0], 1; i < len(parts); i++ {
@@ -119,17 +355,18 @@ func makeTree(nodes []treemap.Node) (*treemap.Tree, error) {
 	}
 
 	var roots []string
-	for node, has := range hasParent {
-		if !has {
+	for _, node := range candidates {
+		if !hasParent[node] {
 			roots = append(roots, node)
 		}
 	}
 
+	// paths can not form cycles, so no roots means no nodes
 	switch {
 	case len(roots) == 0:
-		return nil, errors.New("no roots, possible cycle in graph")
+		return nil, errors.New("no roots, no nodes")
 	case len(roots) > 1:
-		tree.Root = "some-secret-string"
+		tree.Root = treemap.FakeRoot
 		tree.To[tree.Root] = roots
 	default:
 		tree.Root = roots[0]
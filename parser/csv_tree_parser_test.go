@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -80,7 +81,7 @@ func TestMakeTree(t *testing.T) {
 			name:    "when no roots, then error",
 			nodes:   []treemap.Node{},
 			expTree: nil,
-			expErr:  "cycle",
+			expErr:  "no nodes",
 		},
 		{
 			name: "when two roots, then making fake root",
@@ -96,11 +97,11 @@ func TestMakeTree(t *testing.T) {
 					"b/d": {Path: "b/d"},
 				},
 				To: map[string][]string{
-					"a":                  {"a/b"},
-					"b":                  {"b/d"},
-					"some-secret-string": {"a", "b"},
+					"a":              {"a/b"},
+					"b":              {"b/d"},
+					treemap.FakeRoot: {"a", "b"},
 				},
-				Root: "some-secret-string",
+				Root: treemap.FakeRoot,
 			},
 		},
 		{
@@ -156,6 +157,18 @@ func TestMakeTree(t *testing.T) {
 	}
 }
 
+func TestMakeTreeRootsInOrderOfInput(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		tree, err := makeTree([]treemap.Node{{Path: "c/a"}, {Path: "a/b"}, {Path: "b"}, {Path: "c/d"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if roots := tree.To[tree.Root]; !reflect.DeepEqual(roots, []string{"c", "a", "b"}) {
+			t.Errorf("roots: got(%v)", roots)
+		}
+	}
+}
+
 func TestParseNodes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -168,10 +181,12 @@ func TestParseNodes(t *testing.T) {
 			in:   "a/b/c,10,11",
 			expNodes: []treemap.Node{
 				{
-					Path:    "a/b/c",
-					Size:    10,
-					Heat:    11,
-					HasHeat: true,
+					Path:       "a/b/c",
+					Size:       10,
+					Heat:       11,
+					HasHeat:    true,
+					RawHeat:    11,
+					HasRawHeat: true,
 				},
 			},
 		},
@@ -180,10 +195,12 @@ func TestParseNodes(t *testing.T) {
 			in:   "a\"b\",1,1",
 			expNodes: []treemap.Node{
 				{
-					Path:    "a\"b\"",
-					Size:    1,
-					Heat:    1,
-					HasHeat: true,
+					Path:       "a\"b\"",
+					Size:       1,
+					Heat:       1,
+					HasHeat:    true,
+					RawHeat:    1,
+					HasRawHeat: true,
 				},
 			},
 		},
@@ -192,10 +209,12 @@ func TestParseNodes(t *testing.T) {
 			in:   "\"ab\",1,1",
 			expNodes: []treemap.Node{
 				{
-					Path:    "ab",
-					Size:    1,
-					Heat:    1,
-					HasHeat: true,
+					Path:       "ab",
+					Size:       1,
+					Heat:       1,
+					HasHeat:    true,
+					RawHeat:    1,
+					HasRawHeat: true,
 				},
 			},
 		},
@@ -209,6 +228,16 @@ func TestParseNodes(t *testing.T) {
 			in:     ",1,\n\n",
 			expErr: "is not float",
 		},
+		{
+			name:   "when heat is NaN, then error",
+			in:     "a,1,NaN",
+			expErr: "heat(NaN) is not finite",
+		},
+		{
+			name:   "when heat is Inf, then error",
+			in:     "a,1,-Inf",
+			expErr: "heat(-Inf) is not finite",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -220,7 +249,7 @@ func TestParseNodes(t *testing.T) {
 				t.Error("wrong len")
 			}
 			for i := range nodes {
-				if tc.expNodes[i] != nodes[i] {
+				if !reflect.DeepEqual(tc.expNodes[i], nodes[i]) {
 					t.Error("wrong node")
 				}
 			}
@@ -228,6 +257,227 @@ func TestParseNodes(t *testing.T) {
 	}
 }
 
+func TestParseNodesWithHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		parser   CSVTreeParser
+		expNodes []treemap.Node
+		expErr   string
+	}{
+		{
+			name:   "when default column names, then works",
+			in:     "path,size,heat\na/b,10,11",
+			parser: CSVTreeParser{HasHeader: true},
+			expNodes: []treemap.Node{
+				{Path: "a/b", Size: 10, Heat: 11, HasHeat: true, RawHeat: 11, HasRawHeat: true},
+			},
+		},
+		{
+			name:   "when named columns in any order, then picks them and keeps other columns as attributes",
+			in:     "owner,churn,file,bytes,lang\nteam-a,3,a/b.go,100,go",
+			parser: CSVTreeParser{HasHeader: true, PathColumn: "file", SizeColumn: "bytes", HeatColumn: "churn"},
+			expNodes: []treemap.Node{
+				{
+					Path:       "a/b.go",
+					Size:       100,
+					Heat:       3,
+					HasHeat:    true,
+					RawHeat:    3,
+					HasRawHeat: true,
+					Attributes: map[string]string{"owner": "team-a", "lang": "go"},
+				},
+			},
+		},
+		{
+			name:   "when no path column in header, then first column is path",
+			in:     "file,bytes\na/b,10",
+			parser: CSVTreeParser{HasHeader: true, SizeColumn: "bytes"},
+			expNodes: []treemap.Node{
+				{Path: "a/b", Size: 10},
+			},
+		},
+		{
+			name:   "when no path column in header and first column is size, then next column is path",
+			in:     "size,name\n10,a/b",
+			parser: CSVTreeParser{HasHeader: true},
+			expNodes: []treemap.Node{
+				{Path: "a/b", Size: 10},
+			},
+		},
+		{
+			name:   "when no path column in header and only size and heat, then error",
+			in:     "size,heat\n10,1",
+			parser: CSVTreeParser{HasHeader: true},
+			expErr: "no column for path in header",
+		},
+		{
+			name:   "when empty values, then skipped",
+			in:     "path,size,heat,owner\na/b,,,",
+			parser: CSVTreeParser{HasHeader: true},
+			expNodes: []treemap.Node{
+				{Path: "a/b"},
+			},
+		},
+		{
+			name:   "when requested column is missing, then error",
+			in:     "path,size\na/b,10",
+			parser: CSVTreeParser{HasHeader: true, HeatColumn: "churn"},
+			expErr: "heat column(churn) is not in header",
+		},
+		{
+			name:   "when wrong number, then error",
+			in:     "path,size\na/b,x",
+			parser: CSVTreeParser{HasHeader: true},
+			expErr: "is not float",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := parseNodesWithHeader(tc.in, tc.parser.columns())
+
+			assertError(t, err, tc.expErr)
+
+			if !reflect.DeepEqual(tc.expNodes, nodes) {
+				t.Errorf("nodes: exp(%#v) != got(%#v)", tc.expNodes, nodes)
+			}
+		})
+	}
+}
+
+func TestParseLevelColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		levels []string
+		expIn  string
+		expErr string
+	}{
+		{
+			name:   "when all levels, then same as path",
+			levels: []string{"region", "country", "city"},
+			in:     "region,country,city,population\nEurope,France,Paris,2100000\nEurope,France,Lyon,500000\nAsia,Japan,Tokyo,14000000",
+			expIn:  "Europe/France/Paris,2100000\nEurope/France/Lyon,500000\nAsia/Japan/Tokyo,14000000",
+		},
+		{
+			name:   "when empty trailing levels, then node is higher in hierarchy",
+			levels: []string{"region", "country", "city"},
+			in:     "region,country,city,population\nEurope,France,,68000000\nEurope,France,Paris,2100000\nEurope,,,740000000",
+			expIn:  "Europe/France,68000000\nEurope/France/Paris,2100000\nEurope,740000000",
+		},
+		{
+			name:   "when slash in level, then escaped",
+			levels: []string{"region", "country"},
+			in:     "region,country,population\nAfrica,Guinea/Bissau,2000000",
+			expIn:  "Africa/Guinea&sol;Bissau,2000000",
+		},
+		{
+			name:   "when empty level in the middle, then error",
+			levels: []string{"region", "country", "city"},
+			in:     "region,country,city,population\nEurope,,Paris,2100000",
+			expErr: "follows empty level",
+		},
+		{
+			name:   "when all levels empty, then error",
+			levels: []string{"region", "country"},
+			in:     "region,country,population\n,,10",
+			expErr: "all levels are empty",
+		},
+		{
+			name:   "when level column missing, then error",
+			levels: []string{"region", "country"},
+			in:     "region,population\nEurope,10",
+			expErr: "level column(country) is not in header",
+		},
+		{
+			name:   "when size column missing, then error",
+			levels: []string{"region", "country"},
+			in:     "region,country,populaton\nEurope,France,10",
+			expErr: "size column(population) is not in header",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := CSVTreeParser{HasHeader: true, SizeColumn: "population", LevelColumns: tc.levels}
+			tree, err := p.ParseString(tc.in)
+
+			assertError(t, err, tc.expErr)
+			if tc.expErr != "" {
+				return
+			}
+
+			expTree, err := CSVTreeParser{}.ParseString(tc.expIn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !eqTree(*expTree, *tree) {
+				t.Errorf("tree: exp(%#v) != got(%#v)", expTree, tree)
+			}
+		})
+	}
+}
+
+func TestParseSizePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		policy  treemap.SizePolicy
+		expSize float64
+		expErr  string
+	}{
+		{name: "when keep and negative, then same", in: "a,-2", policy: treemap.KeepInvalidSize, expSize: -2},
+		{name: "when no policy and negative, then same", in: "a,-2", expSize: -2},
+		{name: "when reject and valid, then same", in: "a,2", policy: treemap.RejectInvalidSize, expSize: 2},
+		{name: "when reject and negative, then error", in: "a,-2", policy: treemap.RejectInvalidSize, expErr: "node(a): size(-2) is negative"},
+		{name: "when reject and NaN, then error", in: "a,NaN", policy: treemap.RejectInvalidSize, expErr: "node(a): size(NaN) is not finite"},
+		{name: "when reject and Inf, then error", in: "a,Inf", policy: treemap.RejectInvalidSize, expErr: "node(a): size(+Inf) is not finite"},
+		{name: "when clamp and negative, then zero", in: "a,-2", policy: treemap.ClampInvalidSize, expSize: 0},
+		{name: "when clamp and NaN, then zero", in: "a,NaN", policy: treemap.ClampInvalidSize, expSize: 0},
+		{name: "when clamp and Inf, then zero", in: "a,+Inf", policy: treemap.ClampInvalidSize, expSize: 0},
+		{name: "when abs and negative, then positive", in: "a,-2", policy: treemap.AbsInvalidSize, expSize: 2},
+		{name: "when abs and NaN, then zero", in: "a,NaN", policy: treemap.AbsInvalidSize, expSize: 0},
+		{name: "when abs and Inf, then zero", in: "a,-Inf", policy: treemap.AbsInvalidSize, expSize: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := CSVTreeParser{SizePolicy: tc.policy}.ParseString(tc.in)
+			assertError(t, err, tc.expErr)
+			if tc.expErr != "" {
+				return
+			}
+			if size := tree.Nodes["a"].Size; size != tc.expSize {
+				t.Errorf("size: exp(%v) != got(%v)", tc.expSize, size)
+			}
+
+			edgesTree, err := EdgeListTreeParser{SizePolicy: tc.policy}.ParseString("a,,a," + strings.TrimPrefix(tc.in, "a,"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if size := edgesTree.Nodes["a"].Size; size != tc.expSize {
+				t.Errorf("edges size: exp(%v) != got(%v)", tc.expSize, size)
+			}
+		})
+	}
+}
+
+func TestNumericAttribute(t *testing.T) {
+	tree, err := CSVTreeParser{HasHeader: true}.ParseString("path,size,loc,owner\na/b,10,42,team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := tree.Nodes["a/b"]
+	if v, ok := node.NumericAttribute("loc"); !ok || v != 42 {
+		t.Errorf("loc: exp(42) != got(%v, %v)", v, ok)
+	}
+	if _, ok := node.NumericAttribute("owner"); ok {
+		t.Error("owner: expected not numeric")
+	}
+	if v := node.Attributes["owner"]; v != "team-a" {
+		t.Errorf("owner: exp(team-a) != got(%s)", v)
+	}
+}
+
 func assertError(t *testing.T, err error, expErr string) {
 	if expErr == "" && err != nil {
 		t.Error(err)
@@ -252,7 +502,7 @@ func eqTree(a, b treemap.Tree) bool {
 		return false
 	}
 	for k, v := range a.Nodes {
-		if b.Nodes[k] != v {
+		if !reflect.DeepEqual(b.Nodes[k], v) {
 			return false
 		}
 	}
@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+// EdgeListTreeParser parses CSV where each row is a node with reference to its parent.
+// Format is header-less: <id>,<parent id>,<name>,<size>,<heat>.
+// Root has empty parent id. Name, size and heat are optional, name defaults to id.
+// Path of node is ids from root joined by slash, slashes in ids are escaped as HTML entity.
+// Names are set by parser, so there is no need to set names from paths.
+type EdgeListTreeParser struct {
+	HasHeader  bool               // first row is header and is skipped
+	SizePolicy treemap.SizePolicy // what to do with negative, NaN and Inf sizes, default is to keep them
+}
+
+type edge struct {
+	row    int
+	id     string
+	parent string
+	node   treemap.Node
+}
+
+func (s EdgeListTreeParser) ParseString(in string) (*treemap.Tree, error) {
+	edges, err := parseEdges(in, s.HasHeader)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse edges: %w", err)
+	}
+
+	for i, e := range edges {
+		if edges[i].node.Size, err = s.SizePolicy.SanitizeSize(e.node.Size); err != nil {
+			return nil, fmt.Errorf("row(%d): %w", e.row, err)
+		}
+	}
+
+	tree, err := makeTreeFromEdges(edges)
+	if err != nil {
+		return nil, fmt.Errorf("can not make tree: %w", err)
+	}
+
+	return tree, nil
+}
+
+func parseEdges(in string, hasHeader bool) ([]edge, error) {
+	var edges []edge
+	r := csv.NewReader(strings.NewReader(in))
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can not parse: %w", err)
+		}
+		if hasHeader && row == 1 {
+			continue
+		}
+
+		if len(record) < 2 {
+			return nil, fmt.Errorf("row(%d) has %d values, expected at least id and parent id", row, len(record))
+		}
+
+		e := edge{row: row, id: record[0], parent: record[1]}
+		if e.id == "" {
+			return nil, fmt.Errorf("row(%d) has empty id", row)
+		}
+
+		e.node.Name = e.id
+		if len(record) >= 3 && record[2] != "" {
+			e.node.Name = record[2]
+		}
+
+		if len(record) >= 4 && record[3] != "" {
+			v, err := strconv.ParseFloat(record[3], 64)
+			if err != nil {
+				return nil, fmt.Errorf("row(%d) size(%s) is not float: %w", row, record[3], err)
+			}
+			e.node.Size = v
+		}
+
+		if len(record) >= 5 && record[4] != "" {
+			v, err := parseHeat(record[4])
+			if err != nil {
+				return nil, fmt.Errorf("row(%d) %w", row, err)
+			}
+			e.node.Heat = v
+			e.node.RawHeat = v
+			e.node.HasRawHeat = true
+			e.node.HasHeat = true
+		}
+
+		edges = append(edges, e)
+	}
+	return edges, nil
+}
+
+// makeTreeFromEdges checks that edges form single tree and assigns paths from root.
+func makeTreeFromEdges(edges []edge) (*treemap.Tree, error) {
+	if len(edges) == 0 {
+		return nil, errors.New("no nodes")
+	}
+
+	byID := make(map[string]edge, len(edges))
+	for _, e := range edges {
+		if prev, ok := byID[e.id]; ok {
+			return nil, fmt.Errorf("duplicate id(%s) in rows %d and %d", e.id, prev.row, e.row)
+		}
+		byID[e.id] = e
+	}
+
+	var roots []string
+	var orphans []string
+	children := map[string][]string{}
+	for _, e := range edges {
+		switch _, ok := byID[e.parent]; {
+		case e.parent == "":
+			roots = append(roots, e.id)
+		case !ok:
+			orphans = append(orphans, fmt.Sprintf("%s(parent %s)", e.id, e.parent))
+		default:
+			children[e.parent] = append(children[e.parent], e.id)
+		}
+	}
+
+	if len(orphans) > 0 {
+		return nil, fmt.Errorf("nodes with missing parents: %s", strings.Join(orphans, ", "))
+	}
+	if len(roots) > 1 {
+		return nil, fmt.Errorf("multiple roots: %s", strings.Join(roots, ", "))
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no roots, cycle of parents: %s", findCycle(byID, edges[0].id))
+	}
+
+	tree := treemap.Tree{
+		Nodes: make(map[string]treemap.Node, len(edges)),
+		To:    map[string][]string{},
+		Root:  slashEscaper.Replace(roots[0]),
+	}
+
+	paths := map[string]string{roots[0]: tree.Root}
+	que := []string{roots[0]}
+	var q string
+	for len(que) > 0 {
+		q, que = que[0], que[1:]
+
+		node := byID[q].node
+		node.Path = paths[q]
+		tree.Nodes[node.Path] = node
+
+		for _, child := range children[q] {
+			paths[child] = node.Path + "/" + slashEscaper.Replace(child)
+			tree.To[node.Path] = append(tree.To[node.Path], paths[child])
+			que = append(que, child)
+		}
+	}
+
+	// all nodes have existing parents, so unreachable nodes are in cycles
+	for _, e := range edges {
+		if _, ok := paths[e.id]; !ok {
+			return nil, fmt.Errorf("cycle of parents: %s", findCycle(byID, e.id))
+		}
+	}
+
+	return &tree, nil
+}
+
+// findCycle follows parents from node until one repeats.
+// Expects that every node has parent.
+func findCycle(byID map[string]edge, id string) string {
+	seen := map[string]bool{}
+	for !seen[id] {
+		seen[id] = true
+		id = byID[id].parent
+	}
+
+	cycle := []string{id}
+	for q := byID[id].parent; q != id; q = byID[q].parent {
+		cycle = append(cycle, q)
+	}
+	cycle = append(cycle, id)
+
+	return strings.Join(cycle, " -> ")
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestEdgeListTreeParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		parser  EdgeListTreeParser
+		expTree *treemap.Tree
+		expErr  string
+	}{
+		{
+			name: "when basic case, then works",
+			in:   "ceo,,Alice,1,0.5\ncto,ceo,Bob,2\neng,cto,,3,0.1\ncfo,ceo,Carol",
+			expTree: &treemap.Tree{
+				Nodes: map[string]treemap.Node{
+					"ceo":         {Path: "ceo", Name: "Alice", Size: 1, Heat: 0.5, HasHeat: true, RawHeat: 0.5, HasRawHeat: true},
+					"ceo/cto":     {Path: "ceo/cto", Name: "Bob", Size: 2},
+					"ceo/cto/eng": {Path: "ceo/cto/eng", Name: "eng", Size: 3, Heat: 0.1, HasHeat: true, RawHeat: 0.1, HasRawHeat: true},
+					"ceo/cfo":     {Path: "ceo/cfo", Name: "Carol"},
+				},
+				To: map[string][]string{
+					"ceo":     {"ceo/cto", "ceo/cfo"},
+					"ceo/cto": {"ceo/cto/eng"},
+				},
+				Root: "ceo",
+			},
+		},
+		{
+			name:   "when header, then skipped",
+			in:     "id,parent_id,name,size,heat\n1,,root,1,1\n2,1,child,1,1",
+			parser: EdgeListTreeParser{HasHeader: true},
+			expTree: &treemap.Tree{
+				Nodes: map[string]treemap.Node{
+					"1":   {Path: "1", Name: "root", Size: 1, Heat: 1, HasHeat: true, RawHeat: 1, HasRawHeat: true},
+					"1/2": {Path: "1/2", Name: "child", Size: 1, Heat: 1, HasHeat: true, RawHeat: 1, HasRawHeat: true},
+				},
+				To: map[string][]string{
+					"1": {"1/2"},
+				},
+				Root: "1",
+			},
+		},
+		{
+			name: "when slash in id, then escaped",
+			in:   "a/b,,\nc,a/b",
+			expTree: &treemap.Tree{
+				Nodes: map[string]treemap.Node{
+					"a&sol;b":   {Path: "a&sol;b", Name: "a/b"},
+					"a&sol;b/c": {Path: "a&sol;b/c", Name: "c"},
+				},
+				To: map[string][]string{
+					"a&sol;b": {"a&sol;b/c"},
+				},
+				Root: "a&sol;b",
+			},
+		},
+		{
+			name:   "when no rows, then error",
+			in:     "",
+			expErr: "no nodes",
+		},
+		{
+			name:   "when duplicate ids, then error",
+			in:     "a,\nb,a\nb,a",
+			expErr: "duplicate id(b) in rows 2 and 3",
+		},
+		{
+			name:   "when parent is missing, then error",
+			in:     "a,\nb,x\nc,y",
+			expErr: "nodes with missing parents: b(parent x), c(parent y)",
+		},
+		{
+			name:   "when multiple roots, then error",
+			in:     "a,\nb,\nc,a",
+			expErr: "multiple roots: a, b",
+		},
+		{
+			name:   "when all nodes in cycle, then error",
+			in:     "a,c\nb,a\nc,b",
+			expErr: "no roots, cycle of parents: a -> c -> b -> a",
+		},
+		{
+			name:   "when cycle detached from root, then error",
+			in:     "r,\na,r\nb,c\nc,b",
+			expErr: "cycle of parents: b -> c -> b",
+		},
+		{
+			name:   "when node is own parent, then error",
+			in:     "r,\na,a",
+			expErr: "cycle of parents: a -> a",
+		},
+		{
+			name:   "when empty id, then error",
+			in:     "a,\n,a",
+			expErr: "row(2) has empty id",
+		},
+		{
+			name:   "when wrong number, then error",
+			in:     "a,,a,x",
+			expErr: "is not float",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := tc.parser.ParseString(tc.in)
+
+			assertError(t, err, tc.expErr)
+
+			if tc.expTree == nil && tree != nil {
+				t.Error("got tree not nil, expected nil")
+			}
+			if tc.expTree != nil {
+				if tree == nil {
+					t.Error("got tree nil, expected not nil")
+					return
+				}
+				if !eqTree(*tc.expTree, *tree) {
+					t.Errorf("tree: exp(%#v) != got(%#v)", tc.expTree, tree)
+				}
+			}
+		})
+	}
+}
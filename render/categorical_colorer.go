@@ -0,0 +1,105 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+// CategoricalColorer assigns color from qualitative palette to each category of nodes.
+// Category is string attribute of node, or name of top-level ancestor if attribute is not set.
+// Node without category inherits it from closest ancestor, or from descendants if all of them have same category.
+// Other nodes are not colored.
+// Categories get colors in order of appearance from root, palette repeats if there are more categories than colors.
+type CategoricalColorer struct {
+	Categories   []string               // categories in order of appearance
+	Colors       map[string]color.Color // category -> color
+	NodeCategory map[string]string      // node -> category
+}
+
+// NewCategoricalColorer finds categories of all nodes in tree.
+// If attribute is empty, then category is top-level ancestor.
+func NewCategoricalColorer(tree treemap.Tree, palette []color.Color, attribute string) CategoricalColorer {
+	s := CategoricalColorer{
+		Colors:       map[string]color.Color{},
+		NodeCategory: map[string]string{},
+	}
+
+	own := func(node string, depth int) string {
+		if attribute != "" {
+			return tree.Nodes[node].Attributes[attribute]
+		}
+		if depth == 1 {
+			if name := tree.Nodes[node].Name; name != "" {
+				return entityToSlash.Replace(name)
+			}
+			return node
+		}
+		return ""
+	}
+
+	// returns category of subtree and whether subtree has more than one category
+	var visit func(node string, depth int, inherited string) (string, bool)
+	visit = func(node string, depth int, inherited string) (string, bool) {
+		category := own(node, depth)
+		if category != "" {
+			if _, ok := s.Colors[category]; !ok && len(palette) > 0 {
+				s.Colors[category] = palette[len(s.Categories)%len(palette)]
+				s.Categories = append(s.Categories, category)
+			}
+		} else {
+			category = inherited
+		}
+
+		subtree, mixed := category, false
+		for _, child := range tree.To[node] {
+			c, m := visit(child, depth+1, category)
+			switch {
+			case m:
+				mixed = true
+			case c == "":
+				continue
+			case subtree == "":
+				subtree = c
+			case subtree != c:
+				mixed = true
+			}
+		}
+
+		switch {
+		case category != "":
+			s.NodeCategory[node] = category
+		case !mixed && subtree != "":
+			s.NodeCategory[node] = subtree
+		}
+
+		return subtree, mixed
+	}
+	visit(tree.Root, 0, "")
+
+	return s
+}
+
+func (s CategoricalColorer) ColorBox(tree treemap.Tree, node string) color.Color {
+	if c, ok := s.Colors[s.NodeCategory[node]]; ok {
+		return c
+	}
+	return color.Transparent
+}
+
+func (s CategoricalColorer) ColorText(tree treemap.Tree, node string) color.Color {
+	return ContrastTextColor(s.ColorBox(tree, node))
+}
+
+func (s CategoricalColorer) Category(tree treemap.Tree, node string) string {
+	return s.NodeCategory[node]
+}
+
+// Legend has entry for each category.
+func (s CategoricalColorer) Legend() []LegendItem {
+	items := make([]LegendItem, len(s.Categories))
+	for i, category := range s.Categories {
+		items[i] = LegendItem{Label: category, Color: s.Colors[category]}
+	}
+	return items
+}
@@ -0,0 +1,153 @@
+package render
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestCategoricalColorer(t *testing.T) {
+	palette := []color.Color{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}}
+
+	tests := []struct {
+		name          string
+		tree          treemap.Tree
+		attribute     string
+		expCategories []string
+		expNodes      map[string]string
+	}{
+		{
+			name: "when no attribute, then top-level ancestor",
+			tree: treemap.Tree{
+				Nodes: map[string]treemap.Node{
+					"a":   {Path: "a", Name: "a"},
+					"a/b": {Path: "a/b", Name: "b"},
+					"c":   {Path: "c", Name: "c"},
+				},
+				To: map[string][]string{
+					treemap.FakeRoot: {"a", "c"},
+					"a":              {"a/b"},
+				},
+				Root: treemap.FakeRoot,
+			},
+			expCategories: []string{"a", "c"},
+			expNodes: map[string]string{
+				"a":   "a",
+				"a/b": "a",
+				"c":   "c",
+			},
+		},
+		{
+			name: "when attribute, then inherited by descendants and by ancestors with single category",
+			tree: treemap.Tree{
+				Nodes: map[string]treemap.Node{
+					"r":       {Path: "r"},
+					"r/x":     {Path: "r/x"},
+					"r/x/1":   {Path: "r/x/1", Attributes: map[string]string{"team": "core"}},
+					"r/x/2":   {Path: "r/x/2", Attributes: map[string]string{"team": "core"}},
+					"r/y":     {Path: "r/y", Attributes: map[string]string{"team": "ui"}},
+					"r/y/1":   {Path: "r/y/1"},
+					"r/z":     {Path: "r/z"},
+					"r/z/1":   {Path: "r/z/1", Attributes: map[string]string{"team": "core"}},
+					"r/z/2":   {Path: "r/z/2", Attributes: map[string]string{"team": "data"}},
+					"r/other": {Path: "r/other"},
+				},
+				To: map[string][]string{
+					"r":   {"r/x", "r/y", "r/z", "r/other"},
+					"r/x": {"r/x/1", "r/x/2"},
+					"r/y": {"r/y/1"},
+					"r/z": {"r/z/1", "r/z/2"},
+				},
+				Root: "r",
+			},
+			attribute:     "team",
+			expCategories: []string{"core", "ui", "data"},
+			expNodes: map[string]string{
+				"r/x":   "core",
+				"r/x/1": "core",
+				"r/x/2": "core",
+				"r/y":   "ui",
+				"r/y/1": "ui",
+				"r/z/1": "core",
+				"r/z/2": "data",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			colorer := NewCategoricalColorer(tc.tree, palette, tc.attribute)
+
+			if !reflect.DeepEqual(tc.expCategories, colorer.Categories) {
+				t.Errorf("categories: exp(%v) != got(%v)", tc.expCategories, colorer.Categories)
+			}
+			if !reflect.DeepEqual(tc.expNodes, colorer.NodeCategory) {
+				t.Errorf("nodes: exp(%v) != got(%v)", tc.expNodes, colorer.NodeCategory)
+			}
+
+			// palette repeats
+			for i, category := range colorer.Categories {
+				if colorer.Colors[category] != palette[i%len(palette)] {
+					t.Errorf("category(%s) has wrong color", category)
+				}
+			}
+
+			legend := colorer.Legend()
+			if len(legend) != len(tc.expCategories) {
+				t.Errorf("legend: exp(%d) != got(%d)", len(tc.expCategories), len(legend))
+			}
+		})
+	}
+}
+
+func TestLegendReducesTreemapArea(t *testing.T) {
+	tree := makeWideTree(1, 3)
+	builder := UITreeMapBuilder{
+		Colorer:     NoneColorer{},
+		BorderColor: color.White,
+		Legend:      []LegendItem{{Label: "a", Color: color.Black}, {Label: "b", Color: color.White}},
+	}
+
+	spec := builder.NewUITreeMap(tree, 400, 300, 4, 4, 32)
+
+	if len(spec.Legend) != 2 {
+		t.Fatalf("legend: exp(2) != got(%d)", len(spec.Legend))
+	}
+	for _, item := range spec.Legend {
+		if item.Swatch.Y < spec.Y+spec.H {
+			t.Errorf("legend item(%s) overlaps treemap", item.Label.Text)
+		}
+		if item.Swatch.Y+item.Swatch.H > 300-32 {
+			t.Errorf("legend item(%s) is outside of root", item.Label.Text)
+		}
+	}
+}
+
+func TestLegendFromColorer(t *testing.T) {
+	tree := makeWideTree(1, 3)
+	palette := []color.Color{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}}
+	categorical := NewCategoricalColorer(tree, palette, "")
+
+	tests := []struct {
+		name    string
+		colorer Colorer
+	}{
+		{name: "when categorical colorer, then legend of colorer", colorer: categorical},
+		{name: "when highlight of categorical colorer, then legend of categorical colorer", colorer: HighlightColorer{Colorer: categorical}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := UITreeMapBuilder{Colorer: tc.colorer, BorderColor: color.White}
+			spec := builder.NewUITreeMap(tree, 400, 300, 4, 4, 32)
+
+			var got []string
+			for _, item := range spec.Legend {
+				got = append(got, item.Label.Text)
+			}
+			if exp := []string{"0", "1", "2"}; !reflect.DeepEqual(exp, got) {
+				t.Errorf("exp(%v) != got(%v)", exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// categoricalPalettes are qualitative palettes, colors are distinct and have no order.
+// Tableau10 is from Tableau, rest are from ColorBrewer project.
+var categoricalPalettes = map[string][]string{
+	"Tableau10": {"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"},
+	"Set1":      {"#e41a1c", "#377eb8", "#4daf4a", "#984ea3", "#ff7f00", "#ffff33", "#a65628", "#f781bf", "#999999"},
+	"Set2":      {"#66c2a5", "#fc8d62", "#8da0cb", "#e78ac3", "#a6d854", "#ffd92f", "#e5c494", "#b3b3b3"},
+	"Set3":      {"#8dd3c7", "#ffffb3", "#bebada", "#fb8072", "#80b1d3", "#fdb462", "#b3de69", "#fccde5", "#d9d9d9", "#bc80bd", "#ccebc5", "#ffed6f"},
+	"Dark2":     {"#1b9e77", "#d95f02", "#7570b3", "#e7298a", "#66a61e", "#e6ab02", "#a6761d", "#666666"},
+	"Paired":    {"#a6cee3", "#1f78b4", "#b2df8a", "#33a02c", "#fb9a99", "#e31a1c", "#fdbf6f", "#ff7f00", "#cab2d6", "#6a3d9a", "#ffff99", "#b15928"},
+	"Pastel1":   {"#fbb4ae", "#b3cde3", "#ccebc5", "#decbe4", "#fed9a6", "#ffffcc", "#e5d8bd", "#fddaec", "#f2f2f2"},
+}
+
+// GetCategoricalPalette returns qualitative palette by name.
+func GetCategoricalPalette(name string) ([]color.Color, bool) {
+	hexes, ok := categoricalPalettes[name]
+	if !ok {
+		return nil, false
+	}
+	palette := make([]color.Color, len(hexes))
+	for i, hex := range hexes {
+		c, _ := colorful.Hex(hex)
+		palette[i] = c
+	}
+	return palette, true
+}
+
+// CategoricalPaletteNames returns names of all qualitative palettes.
+func CategoricalPaletteNames() []string {
+	names := make([]string, 0, len(categoricalPalettes))
+	for name := range categoricalPalettes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
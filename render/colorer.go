@@ -3,7 +3,6 @@ package render
 import (
 	"image/color"
 
-	"github.com/lucasb-eyer/go-colorful"
 	"github.com/nikolaydubina/treemap"
 )
 
@@ -38,12 +37,5 @@ func (s HeatColorer) ColorBox(tree treemap.Tree, node string) color.Color {
 }
 
 func (s HeatColorer) ColorText(tree treemap.Tree, node string) color.Color {
-	boxColor := s.ColorBox(tree, node).(colorful.Color)
-	_, _, l := boxColor.Hcl()
-	switch {
-	case l > 0.5:
-		return DarkTextColor
-	default:
-		return LightTextColor
-	}
+	return ContrastTextColor(s.ColorBox(tree, node))
 }
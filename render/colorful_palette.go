@@ -1,9 +1,11 @@
 package render
 
 import (
-	_ "embed"
+	"embed"
+	"fmt"
 	"image/color"
-	"log"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,7 +15,10 @@ import (
 // This table contains the "keypoints" of the colorgradient you want to generate.
 // The position of each keypoint has to live in the range [0,1]
 // Ths is copied from go-colorful examples!!!
-type ColorfulPalette []struct {
+type ColorfulPalette []ColorfulPaletteStop
+
+// ColorfulPaletteStop is color at position of gradient.
+type ColorfulPaletteStop struct {
 	Col colorful.Color
 	Pos float64
 }
@@ -36,46 +41,72 @@ func (gt ColorfulPalette) GetInterpolatedColorFor(t float64) color.Color {
 	return gt[len(gt)-1].Col
 }
 
-//go:embed palettes/ReBu.csv
-var paletteReBuCSV string
+//go:embed palettes/*.csv
+var palettesFS embed.FS
 
-//go:embed palettes/RdYlGn.csv
-var paletteRdYlGnCSV string
+// ParsePaletteCSV reads palette from rows of <hex color>,<position>.
+// Positions have to be within [0, 1] and sorted. Palette has to have at least two colors.
+func ParsePaletteCSV(csv string) (ColorfulPalette, error) {
+	var palette ColorfulPalette
 
-func makePaletteFromCSV(csv string) ColorfulPalette {
-	rows := strings.Split(csv, "\n")
-	palette := make(ColorfulPalette, len(rows))
+	for i, row := range strings.Split(csv, "\n") {
+		row = strings.TrimSpace(row)
+		if row == "" {
+			continue
+		}
 
-	for i, row := range rows {
 		parts := strings.Split(row, ",")
 		if len(parts) != 2 {
-			continue
+			return nil, fmt.Errorf("row(%d) has %d values, expected <hex color>,<position>", i+1, len(parts))
 		}
 
-		c, err := colorful.Hex(parts[0])
+		c, err := colorful.Hex(strings.TrimSpace(parts[0]))
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("row(%d) color(%s) is not hex color: %w", i+1, parts[0], err)
 		}
 
-		v, err := strconv.ParseFloat(parts[1], 64)
+		v, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("row(%d) position(%s) is not float: %w", i+1, parts[1], err)
+		}
+		if v < 0 || v > 1 {
+			return nil, fmt.Errorf("row(%d) position(%v) is not within [0, 1]", i+1, v)
+		}
+		if n := len(palette); n > 0 && palette[n-1].Pos > v {
+			return nil, fmt.Errorf("row(%d) position(%v) is less than previous(%v)", i+1, v, palette[n-1].Pos)
 		}
 
-		palette[i].Col = c
-		palette[i].Pos = v
+		palette = append(palette, ColorfulPaletteStop{Col: c, Pos: v})
 	}
 
-	return palette
+	if len(palette) < 2 {
+		return nil, fmt.Errorf("palette has %d colors, expected at least 2", len(palette))
+	}
+
+	return palette, nil
 }
 
+// GetPalette returns one of built-in palettes by name.
 func GetPalette(name string) (ColorfulPalette, bool) {
-	switch name {
-	case "RdBu":
-		return makePaletteFromCSV(paletteReBuCSV), true
-	case "RdYlGn":
-		return makePaletteFromCSV(paletteRdYlGnCSV), true
-	default:
+	csv, err := palettesFS.ReadFile(path.Join("palettes", name+".csv"))
+	if err != nil {
 		return nil, false
 	}
+	palette, err := ParsePaletteCSV(string(csv))
+	if err != nil {
+		// built-in palettes are checked in tests
+		panic(fmt.Errorf("palette(%s): %w", name, err))
+	}
+	return palette, true
+}
+
+// PaletteNames returns names of all built-in palettes.
+func PaletteNames() []string {
+	files, _ := palettesFS.ReadDir("palettes")
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(f.Name(), ".csv"))
+	}
+	sort.Strings(names)
+	return names
 }
@@ -1,6 +1,7 @@
 package render
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/lucasb-eyer/go-colorful"
@@ -14,3 +15,63 @@ func TestGetPaletteBasic(t *testing.T) {
 		t.Errorf("exp(%#v) != got(%#v)", expColor, palette[0].Col)
 	}
 }
+
+func TestBuiltinPalettes(t *testing.T) {
+	names := PaletteNames()
+	for _, name := range []string{"RdBu", "RdYlGn", "viridis", "magma", "inferno", "plasma", "cividis", "PuOr", "BrBG"} {
+		if !strings.Contains(strings.Join(names, ","), name) {
+			t.Errorf("palette(%s) is not listed", name)
+		}
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			palette, ok := GetPalette(name)
+			if !ok {
+				t.Fatal("not found")
+			}
+			if palette[0].Pos != 0 || palette[len(palette)-1].Pos != 1 {
+				t.Errorf("palette does not cover [0, 1]")
+			}
+			if c := palette.GetInterpolatedColorFor(1).(colorful.Color); !c.AlmostEqualRgb(palette[len(palette)-1].Col) {
+				t.Errorf("last color: exp(%v) != got(%v)", palette[len(palette)-1].Col, c)
+			}
+		})
+	}
+
+	if _, ok := GetPalette("unknown"); ok {
+		t.Error("unknown palette is found")
+	}
+}
+
+func TestParsePaletteCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		expLen int
+		expErr string
+	}{
+		{name: "when valid, then parsed", in: "#000000,0\n#ffffff,1\n", expLen: 2},
+		{name: "when windows line endings and spaces, then parsed", in: "#000000, 0\r\n#ffffff ,1\r\n", expLen: 2},
+		{name: "when one color, then error", in: "#000000,0", expErr: "expected at least 2"},
+		{name: "when bad color, then error", in: "black,0\n#ffffff,1", expErr: "row(1) color(black) is not hex color"},
+		{name: "when bad position, then error", in: "#000000,x\n#ffffff,1", expErr: "row(1) position(x) is not float"},
+		{name: "when position out of range, then error", in: "#000000,0\n#ffffff,2", expErr: "row(2) position(2) is not within [0, 1]"},
+		{name: "when positions not sorted, then error", in: "#000000,0.5\n#ffffff,0.1", expErr: "row(2) position(0.1) is less than previous(0.5)"},
+		{name: "when wrong number of values, then error", in: "#000000,0,1\n#ffffff,1", expErr: "row(1) has 3 values"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			palette, err := ParsePaletteCSV(tc.in)
+			if tc.expErr == "" && err != nil {
+				t.Error(err)
+			}
+			if tc.expErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expErr)) {
+				t.Errorf("error: exp(%s) != got(%v)", tc.expErr, err)
+			}
+			if len(palette) != tc.expLen {
+				t.Errorf("len: exp(%d) != got(%d)", tc.expLen, len(palette))
+			}
+		})
+	}
+}
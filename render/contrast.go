@@ -0,0 +1,60 @@
+package render
+
+import (
+	"image/color"
+	"math"
+)
+
+// WCAG 2 minimum contrast ratios for normal text.
+const (
+	ContrastAA  float64 = 4.5
+	ContrastAAA float64 = 7
+)
+
+// RelativeLuminance of color as defined in WCAG 2.
+// Transparent colors are composed over white.
+func RelativeLuminance(c color.Color) float64 {
+	r, g, b, a := c.RGBA()
+	// premultiplied by alpha, composing over white
+	white := float64(0xffff - a)
+	channel := func(v uint32) float64 {
+		s := (float64(v) + white) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return (0.2126 * channel(r)) + (0.7152 * channel(g)) + (0.0722 * channel(b))
+}
+
+// ContrastRatio between two colors as defined in WCAG 2, from 1 to 21.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// ContrastTextColor is dark or light text color, whichever has higher contrast with background.
+// One of them has contrast at least 4.58 with any background.
+func ContrastTextColor(background color.Color) color.Color {
+	if ContrastRatio(DarkTextColor, background) >= ContrastRatio(LightTextColor, background) {
+		return DarkTextColor
+	}
+	return LightTextColor
+}
+
+// composeOver makes opaque color as seen when color is drawn over background.
+func composeOver(c, background color.Color) color.Color {
+	r, g, b, a := c.RGBA()
+	br, bg, bb, _ := background.RGBA()
+	over := func(v, bv uint32) uint16 {
+		if v += bv * (0xffff - a) / 0xffff; v > 0xffff {
+			// colors out of gamut
+			return 0xffff
+		}
+		return uint16(v)
+	}
+	return color.RGBA64{R: over(r, br), G: over(g, bg), B: over(b, bb), A: 0xffff}
+}
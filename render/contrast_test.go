@@ -0,0 +1,96 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		a    color.Color
+		b    color.Color
+		exp  float64
+	}{
+		{name: "black on white", a: color.Black, b: color.White, exp: 21},
+		{name: "same color", a: color.RGBA{120, 30, 200, 255}, b: color.RGBA{120, 30, 200, 255}, exp: 1},
+		{name: "grey on white", a: color.RGBA{118, 118, 118, 255}, b: color.White, exp: 4.54},
+		{name: "transparent is white", a: color.Black, b: color.Transparent, exp: 21},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ContrastRatio(tc.a, tc.b); math.Abs(got-tc.exp) > 0.01 {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestTextContrastForEveryPaletteStop(t *testing.T) {
+	for _, name := range PaletteNames() {
+		palette, _ := GetPalette(name)
+		colorer := HeatColorer{Palette: palette}
+		for _, stop := range palette {
+			tree := treemap.Tree{Nodes: map[string]treemap.Node{"a": {Path: "a", Heat: stop.Pos, HasHeat: true}}}
+			box, text := colorer.ColorBox(tree, "a"), colorer.ColorText(tree, "a")
+			if c := ContrastRatio(box, text); c < ContrastAA {
+				t.Errorf("palette(%s) position(%v) color(%s) has contrast(%.2f)", name, stop.Pos, stop.Col.Hex(), c)
+			}
+		}
+	}
+
+	for _, name := range CategoricalPaletteNames() {
+		palette, _ := GetCategoricalPalette(name)
+		for _, c := range palette {
+			if ratio := ContrastRatio(c, ContrastTextColor(c)); ratio < ContrastAA {
+				t.Errorf("palette(%s) color(%v) has contrast(%.2f)", name, c, ratio)
+			}
+		}
+	}
+}
+
+func TestTextOutline(t *testing.T) {
+	// mid-tone red, black text has contrast about 5.3
+	red := color.RGBA{220, 60, 60, 255}
+
+	tests := []struct {
+		name       string
+		min        float64
+		expOutline color.Color
+	}{
+		{name: "when no minimum, then no outline", min: 0, expOutline: nil},
+		{name: "when contrast is enough, then no outline", min: ContrastAA, expOutline: nil},
+		{name: "when contrast is not enough, then outline of opposite color", min: ContrastAAA, expOutline: LightTextColor},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := UITreeMapBuilder{MinTextContrast: tc.min}
+			if got := builder.textOutline(ContrastTextColor(red), red); got != tc.expOutline {
+				t.Errorf("exp(%v) != got(%v)", tc.expOutline, got)
+			}
+		})
+	}
+}
+
+func TestComposeOver(t *testing.T) {
+	tests := []struct {
+		name       string
+		c          color.Color
+		background color.Color
+		exp        color.RGBA64
+	}{
+		{name: "when opaque, then same color", c: color.White, background: color.Black, exp: color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}},
+		{name: "when transparent, then background", c: color.Transparent, background: color.White, exp: color.RGBA64{0xffff, 0xffff, 0xffff, 0xffff}},
+		{name: "when half transparent, then between", c: color.NRGBA{0, 0, 0, 0x80}, background: color.White, exp: color.RGBA64{0x7f7f, 0x7f7f, 0x7f7f, 0xffff}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := composeOver(tc.c, tc.background); got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
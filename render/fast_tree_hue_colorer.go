@@ -0,0 +1,62 @@
+package render
+
+import (
+	"image/color"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nikolaydubina/treemap"
+)
+
+// chromaSearchIterations is enough for chroma precision better than visible difference
+const chromaSearchIterations = 16
+
+// FastTreeHueColorer uses same hues as TreeHueColorer, but computes colors for all nodes once in single pass.
+// Not all HCL values are valid colors. Instead of sampling, this colorer keeps hue and lightness
+// and finds highest chroma up to target that gives valid color.
+// This is deterministic and fast enough for large trees.
+type FastTreeHueColorer struct {
+	Colors map[string]colorful.Color
+}
+
+// NewFastTreeHueColorer computes colors for each node in tree.
+// C and L are target chroma and lightness, offset is 0 ~ 360 hue offset in HCL for tree.
+func NewFastTreeHueColorer(tree treemap.Tree, offset, c, l float64) FastTreeHueColorer {
+	hues := TreeHues(tree, offset)
+	colors := make(map[string]colorful.Color, len(hues))
+	for node, h := range hues {
+		colors[node] = closestValidHcl(h, c, l)
+	}
+	return FastTreeHueColorer{Colors: colors}
+}
+
+func (s FastTreeHueColorer) ColorBox(tree treemap.Tree, node string) color.Color {
+	if c, ok := s.Colors[node]; ok {
+		return c
+	}
+	// white
+	return colorful.Hcl(0, 0, 1)
+}
+
+func (s FastTreeHueColorer) ColorText(tree treemap.Tree, node string) color.Color {
+	return ContrastTextColor(s.ColorBox(tree, node))
+}
+
+// closestValidHcl finds valid color with same hue and lightness and highest chroma up to c.
+// Grey of any lightness is valid, so binary search on chroma always finds color.
+func closestValidHcl(h, c, l float64) colorful.Color {
+	if col := colorful.Hcl(h, c, l); col.IsValid() {
+		return col
+	}
+
+	lo, hi := 0.0, c
+	for i := 0; i < chromaSearchIterations; i++ {
+		mid := (lo + hi) / 2
+		if colorful.Hcl(h, mid, l).IsValid() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return colorful.Hcl(h, lo, l).Clamped()
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestFastTreeHueColorerValidColors(t *testing.T) {
+	tree := makeWideTree(3, 8)
+	colorer := NewFastTreeHueColorer(tree, 0, 0.5, 0.5)
+
+	if len(colorer.Colors) != len(tree.Nodes)+1 {
+		t.Errorf("colors: exp(%d) != got(%d)", len(tree.Nodes)+1, len(colorer.Colors))
+	}
+	for node, c := range colorer.Colors {
+		if !c.IsValid() {
+			t.Errorf("node(%s) color(%#v) is not valid", node, c)
+		}
+		if _, _, l := c.Hcl(); l < 0.49 || l > 0.51 {
+			t.Errorf("node(%s) lightness(%v) is not close to target", node, l)
+		}
+	}
+}
+
+func TestFastTreeHueColorerDeterministic(t *testing.T) {
+	tree := makeWideTree(2, 6)
+	a := NewFastTreeHueColorer(tree, 0, 0.5, 0.5)
+	b := NewFastTreeHueColorer(tree, 0, 0.5, 0.5)
+	for node := range tree.Nodes {
+		if a.ColorBox(tree, node) != b.ColorBox(tree, node) {
+			t.Errorf("node(%s) has different colors", node)
+		}
+	}
+}
+
+func BenchmarkTreeHueColorer(b *testing.B) {
+	for _, n := range []int{4, 8} {
+		tree := makeWideTree(2, n)
+		b.Run(fmt.Sprintf("nodes_%d", len(tree.Nodes)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				colorer := TreeHueColorer{Hues: TreeHues(tree, 0), C: 0.5, L: 0.5, DeltaH: 10, DeltaC: 0.3, DeltaL: 0.1}
+				for node := range tree.Nodes {
+					colorer.ColorBox(tree, node)
+					colorer.ColorText(tree, node)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFastTreeHueColorer(b *testing.B) {
+	for _, n := range []int{4, 8, 32} {
+		tree := makeWideTree(2, n)
+		b.Run(fmt.Sprintf("nodes_%d", len(tree.Nodes)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				colorer := NewFastTreeHueColorer(tree, 0, 0.5, 0.5)
+				for node := range tree.Nodes {
+					colorer.ColorBox(tree, node)
+					colorer.ColorText(tree, node)
+				}
+			}
+		})
+	}
+}
+
+// makeWideTree makes tree with fake root and n children at each of depth levels.
+func makeWideTree(depth, n int) treemap.Tree {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{},
+		To:    map[string][]string{},
+		Root:  "root",
+	}
+	que := []string{tree.Root}
+	for d := 0; d < depth; d++ {
+		var next []string
+		for _, parent := range que {
+			for i := 0; i < n; i++ {
+				child := fmt.Sprintf("%s/%d", parent, i)
+				tree.Nodes[child] = treemap.Node{Path: child, Name: fmt.Sprint(i), Size: float64(i + 1)}
+				tree.To[parent] = append(tree.To[parent], child)
+				next = append(next, child)
+			}
+		}
+		que = next
+	}
+	return tree
+}
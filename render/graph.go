@@ -0,0 +1,127 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+// graphLabel shows values of nodes after imputation.
+var graphLabel, _ = ParseLabelTemplate(`{name}\nsize {size}\nheat {heat}`)
+
+// DOTRenderer writes tree as Graphviz graph, e.g. to see what collapse of long paths and imputation did to tree.
+// Nodes are in order of tree edges, with path as tooltip and with name, size and heat as label.
+type DOTRenderer struct {
+	Colorer Colorer // fill and text colors of nodes, default is no colors
+}
+
+func (r DOTRenderer) RenderTo(out io.Writer, tree treemap.Tree) error {
+	w := bufio.NewWriter(out)
+
+	io.WriteString(w, "digraph treemap {\n")
+	io.WriteString(w, "\tnode [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+
+	ids := make(map[string]string)
+	walkTree(tree, tree.Root, func(node string) {
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[node] = id
+
+		fmt.Fprintf(w, "\t%s [label=%s, tooltip=%s", id, dotString(strings.Join(graphLabel.Format(tree, node), "\n")), dotString(formatLabelField(tree, node, "path", "")))
+		if r.Colorer != nil {
+			if fill, ok := graphColor(r.Colorer.ColorBox(tree, node)); ok {
+				fmt.Fprintf(w, ", fillcolor=\"%s\"", fill)
+			}
+			if text, ok := graphColor(r.Colorer.ColorText(tree, node)); ok {
+				fmt.Fprintf(w, ", fontcolor=\"%s\"", text)
+			}
+		}
+		io.WriteString(w, "];\n")
+	})
+
+	walkTree(tree, tree.Root, func(node string) {
+		for _, child := range tree.To[node] {
+			fmt.Fprintf(w, "\t%s -> %s;\n", ids[node], ids[child])
+		}
+	})
+
+	io.WriteString(w, "}\n")
+	return w.Flush()
+}
+
+// MermaidRenderer writes tree as Mermaid mindmap with name, size and heat of nodes as label.
+// Mindmap has no styles of nodes, so colors are not rendered.
+type MermaidRenderer struct{}
+
+func (r MermaidRenderer) RenderTo(out io.Writer, tree treemap.Tree) error {
+	w := bufio.NewWriter(out)
+
+	io.WriteString(w, "mindmap\n")
+
+	var n int
+	var write func(node string, depth int)
+	write = func(node string, depth int) {
+		fmt.Fprintf(w, "%sn%d[\"%s\"]\n", strings.Repeat("  ", depth+1), n, mermaidEscaper.Replace(strings.Join(graphLabel.Format(tree, node), "\n")))
+		n++
+		for _, child := range tree.To[node] {
+			write(child, depth+1)
+		}
+	}
+	write(tree.Root, 0)
+
+	return w.Flush()
+}
+
+// walkTree visits nodes from root in order of edges, parents before children.
+func walkTree(tree treemap.Tree, node string, visit func(node string)) {
+	visit(node)
+	for _, child := range tree.To[node] {
+		walkTree(tree, child, visit)
+	}
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotString is quoted string, new lines are centered lines of label.
+func dotString(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// mermaidEscaper replaces characters of syntax by entity codes, new lines are line breaks.
+var mermaidEscaper = strings.NewReplacer(
+	"#", "#35;",
+	"&", "#amp;",
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+	"\n", "<br/>",
+)
+
+// graphColor is hex of color with opacity when color is not opaque, transparent color has no hex.
+func graphColor(c color.Color) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return "", false
+	}
+
+	var s strings.Builder
+	s.WriteString("#")
+	for _, v := range []uint32{r, g, b} {
+		// colors without premultiplication, clamped for colors out of gamut
+		v = v * 0xff / a
+		if v > 0xff {
+			v = 0xff
+		}
+		fmt.Fprintf(&s, "%02x", v)
+	}
+	if a < 0xffff {
+		fmt.Fprintf(&s, "%02x", a>>8)
+	}
+	return s.String(), true
+}
@@ -0,0 +1,139 @@
+package render
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func graphTestTree() treemap.Tree {
+	return treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":             {Path: "a", Name: "a", Size: 3, Heat: 1, HasHeat: true, RawHeat: 10},
+			"a/b&sol;c":     {Path: "a/b&sol;c", Name: "b&sol;c", Size: 2},
+			"a/\"q\" #1":    {Path: "a/\"q\" #1", Name: "\"q\" #1", Size: 1, HasHeat: true, RawHeat: 0.125},
+			"a/b&sol;c/<d>": {Path: "a/b&sol;c/<d>", Name: "<d>", Size: 2},
+		},
+		To: map[string][]string{
+			"a":         {"a/b&sol;c", "a/\"q\" #1"},
+			"a/b&sol;c": {"a/b&sol;c/<d>"},
+		},
+		Root: "a",
+	}
+}
+
+func TestDOTRenderer(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer DOTRenderer
+		exp      string
+	}{
+		{
+			name:     "when no colorer, then nodes have default color",
+			renderer: DOTRenderer{},
+			exp: `digraph treemap {
+	node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];
+	n0 [label="a\nsize 3\nheat 10", tooltip="a"];
+	n1 [label="b/c\nsize 2", tooltip="a/b/c"];
+	n2 [label="<d>\nsize 2", tooltip="a/b/c/<d>"];
+	n3 [label="\"q\" #1\nsize 1\nheat 0.13", tooltip="a/\"q\" #1"];
+	n0 -> n1;
+	n0 -> n3;
+	n1 -> n2;
+}
+`,
+		},
+		{
+			name:     "when colorer, then nodes have colors of colorer",
+			renderer: DOTRenderer{Colorer: testColorer{box: color.NRGBA{R: 255, G: 0, B: 0, A: 128}, text: color.White}},
+			exp: `digraph treemap {
+	node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];
+	n0 [label="a\nsize 3\nheat 10", tooltip="a", fillcolor="#ff000080", fontcolor="#ffffff"];
+	n1 [label="b/c\nsize 2", tooltip="a/b/c", fillcolor="#ff000080", fontcolor="#ffffff"];
+	n2 [label="<d>\nsize 2", tooltip="a/b/c/<d>", fillcolor="#ff000080", fontcolor="#ffffff"];
+	n3 [label="\"q\" #1\nsize 1\nheat 0.13", tooltip="a/\"q\" #1", fillcolor="#ff000080", fontcolor="#ffffff"];
+	n0 -> n1;
+	n0 -> n3;
+	n1 -> n2;
+}
+`,
+		},
+		{
+			name:     "when colorer is transparent, then nodes have default color",
+			renderer: DOTRenderer{Colorer: NoneColorer{}},
+			exp: `digraph treemap {
+	node [shape=box, style="rounded,filled", fillcolor="#ffffff", fontname="Helvetica"];
+	n0 [label="a\nsize 3\nheat 10", tooltip="a", fontcolor="#000000"];
+	n1 [label="b/c\nsize 2", tooltip="a/b/c", fontcolor="#000000"];
+	n2 [label="<d>\nsize 2", tooltip="a/b/c/<d>", fontcolor="#000000"];
+	n3 [label="\"q\" #1\nsize 1\nheat 0.13", tooltip="a/\"q\" #1", fontcolor="#000000"];
+	n0 -> n1;
+	n0 -> n3;
+	n1 -> n2;
+}
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tc.renderer.RenderTo(&b, graphTestTree()); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); tc.exp != got {
+				t.Errorf("exp(%s) != got(%s)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestMermaidRenderer(t *testing.T) {
+	exp := `mindmap
+  n0["a<br/>size 3<br/>heat 10"]
+    n1["b/c<br/>size 2"]
+      n2["#lt;d#gt;<br/>size 2"]
+    n3["#quot;q#quot; #35;1<br/>size 1<br/>heat 0.13"]
+`
+	var b bytes.Buffer
+	if err := (MermaidRenderer{}).RenderTo(&b, graphTestTree()); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); exp != got {
+		t.Errorf("exp(%s) != got(%s)", exp, got)
+	}
+}
+
+func TestGraphFakeRoot(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			treemap.FakeRoot: {Path: treemap.FakeRoot, Name: treemap.FakeRoot, Size: 3},
+			"a":              {Path: "a", Name: "a", Size: 1},
+			"b":              {Path: "b", Name: "b", Size: 2},
+		},
+		To:   map[string][]string{treemap.FakeRoot: {"a", "b"}},
+		Root: treemap.FakeRoot,
+	}
+	exp := `mindmap
+  n0["size 3"]
+    n1["a<br/>size 1"]
+    n2["b<br/>size 2"]
+`
+	var b bytes.Buffer
+	if err := (MermaidRenderer{}).RenderTo(&b, tree); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); exp != got {
+		t.Errorf("exp(%s) != got(%s)", exp, got)
+	}
+}
+
+type testColorer struct {
+	box  color.Color
+	text color.Color
+}
+
+func (s testColorer) ColorBox(tree treemap.Tree, node string) color.Color { return s.box }
+
+func (s testColorer) ColorText(tree treemap.Tree, node string) color.Color { return s.text }
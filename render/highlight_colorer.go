@@ -0,0 +1,126 @@
+package render
+
+import (
+	"image/color"
+	"regexp"
+	"strings"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nikolaydubina/treemap"
+)
+
+var HighlightBorderColor color.Color = color.RGBA{255, 140, 0, 255}
+
+const HighlightBorderWidth float64 = 3
+
+// Highlighter is Colorer that marks some nodes, boxes of these nodes get border of highlighter.
+type Highlighter interface {
+	Highlight(tree treemap.Tree, node string) (border color.Color, width float64, ok bool)
+}
+
+// HighlightColorer keeps colors of nodes with path that matches pattern and desaturates other nodes.
+// Paths are matched with slashes in names unescaped.
+type HighlightColorer struct {
+	Colorer     Colorer
+	Pattern     *regexp.Regexp
+	BorderColor color.Color // default is HighlightBorderColor
+	BorderWidth float64     // default is HighlightBorderWidth
+}
+
+func (s HighlightColorer) ColorBox(tree treemap.Tree, node string) color.Color {
+	c := s.Colorer.ColorBox(tree, node)
+	if s.isMatch(node) {
+		return c
+	}
+	return desaturate(c)
+}
+
+func (s HighlightColorer) ColorText(tree treemap.Tree, node string) color.Color {
+	return ContrastTextColor(s.ColorBox(tree, node))
+}
+
+func (s HighlightColorer) Highlight(tree treemap.Tree, node string) (border color.Color, width float64, ok bool) {
+	if !s.isMatch(node) {
+		return nil, 0, false
+	}
+	border, width = s.BorderColor, s.BorderWidth
+	if border == nil {
+		border = HighlightBorderColor
+	}
+	if width <= 0 {
+		width = HighlightBorderWidth
+	}
+	return border, width, true
+}
+
+// Category is category of colorer, if it has categories.
+func (s HighlightColorer) Category(tree treemap.Tree, node string) string {
+	if c, ok := s.Colorer.(Categorizer); ok {
+		return c.Category(tree, node)
+	}
+	return ""
+}
+
+// Legend is legend of colorer, if it has one.
+func (s HighlightColorer) Legend() []LegendItem {
+	if l, ok := s.Colorer.(Legender); ok {
+		return l.Legend()
+	}
+	return nil
+}
+
+func (s HighlightColorer) isMatch(node string) bool {
+	return node != treemap.FakeRoot && s.Pattern != nil && s.Pattern.MatchString(entityToSlash.Replace(node))
+}
+
+// desaturate makes grey of same luminance, so that contrast with text is same, transparency is kept.
+func desaturate(c color.Color) color.Color {
+	_, _, _, a := c.RGBA()
+	if a == 0 {
+		return c
+	}
+	col, ok := colorful.MakeColor(c)
+	if !ok {
+		return c
+	}
+	r, g, b := col.LinearRgb()
+	y := (0.2126 * r) + (0.7152 * g) + (0.0722 * b)
+	grey := colorful.LinearRgb(y, y, y).Clamped()
+	if a == 0xffff {
+		return grey
+	}
+	v, _, _ := grey.RGB255()
+	return color.NRGBA{R: v, G: v, B: v, A: uint8(a >> 8)}
+}
+
+// CompileGlob makes pattern that matches whole path.
+// Star matches any part of path segment, double star matches any number of segments, question mark matches single rune.
+func CompileGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 3
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i += 2
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+			i++
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+			i++
+		default:
+			j := strings.IndexAny(glob[i:], "*?")
+			if j < 0 {
+				j = len(glob) - i
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+j]))
+			i += j
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
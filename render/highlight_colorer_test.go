@@ -0,0 +1,109 @@
+package render
+
+import (
+	"image/color"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{glob: "**/*_test.go", path: "render/svg_test.go", match: true},
+		{glob: "**/*_test.go", path: "svg_test.go", match: true},
+		{glob: "**/*_test.go", path: "render/svg.go", match: false},
+		{glob: "render/*", path: "render/svg.go", match: true},
+		{glob: "render/*", path: "render/palettes/RdBu.csv", match: false},
+		{glob: "render/**", path: "render/palettes/RdBu.csv", match: true},
+		{glob: "render/?vg.go", path: "render/svg.go", match: true},
+		{glob: "a.b", path: "axb", match: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.glob+" "+tc.path, func(t *testing.T) {
+			pattern, err := CompileGlob(tc.glob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pattern.MatchString(tc.path); got != tc.match {
+				t.Errorf("exp(%v) != got(%v)", tc.match, got)
+			}
+		})
+	}
+}
+
+func TestHighlightColorer(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":          {Path: "a", Name: "a", Size: 2},
+			"a/b_test.c": {Path: "a/b_test.c", Name: "b_test.c", Size: 1},
+			"a/c.c":      {Path: "a/c.c", Name: "c.c", Size: 1},
+		},
+		To:   map[string][]string{"a": {"a/b_test.c", "a/c.c"}},
+		Root: "a",
+	}
+	red := color.RGBA{220, 60, 60, 255}
+	colorer := HighlightColorer{
+		Colorer: CategoricalColorer{Colors: map[string]color.Color{"x": red}, NodeCategory: map[string]string{"a": "x", "a/b_test.c": "x", "a/c.c": "x"}},
+		Pattern: regexp.MustCompile(`_test\.c$`),
+	}
+
+	if got := colorer.ColorBox(tree, "a/b_test.c"); got != red {
+		t.Errorf("matching node should keep color: exp(%v) != got(%v)", red, got)
+	}
+	if c, _ := colorful.MakeColor(colorer.ColorBox(tree, "a/c.c")); c.R != c.G || c.G != c.B {
+		t.Errorf("other node should be grey, got(%v)", c)
+	}
+
+	spec := UITreeMapBuilder{Colorer: colorer, BorderColor: color.White}.NewUITreeMap(tree, 400, 300, 1, 1, 8)
+	for _, box := range spec.Children[0].Children {
+		expHighlighted := box.Title != nil && box.Title.Text == "b_test.c"
+		if box.Highlighted != expHighlighted {
+			t.Errorf("box(%v): exp(%v) != got(%v)", box.Title, expHighlighted, box.Highlighted)
+		}
+		if expHighlighted && (box.BorderColor != HighlightBorderColor || box.BorderWidth != HighlightBorderWidth) {
+			t.Errorf("highlighted box should have accent border, got(%v, %v)", box.BorderColor, box.BorderWidth)
+		}
+	}
+}
+
+func TestHighlightColorerKeepsCategories(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"r":        {Path: "r", Name: "r", Size: 3},
+			"r/a":      {Path: "r/a", Name: "a", Size: 2},
+			"r/a/x.go": {Path: "r/a/x.go", Name: "x.go", Size: 2},
+			"r/b":      {Path: "r/b", Name: "b", Size: 1},
+		},
+		To: map[string][]string{
+			"r":   {"r/a", "r/b"},
+			"r/a": {"r/a/x.go"},
+		},
+		Root: "r",
+	}
+	palette := []color.Color{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}}
+	pattern, err := CompileGlob("**/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	colorer := HighlightColorer{Colorer: NewCategoricalColorer(tree, palette, ""), Pattern: pattern}
+
+	builder := UITreeMapBuilder{Colorer: colorer, BorderColor: color.White}
+	s := string(SVGRenderer{}.Render(builder.NewUITreeMap(tree, 400, 300, 1, 1, 8), 400, 300))
+
+	for _, exp := range []string{
+		`<g class="box depth-1 internal category-a">`,
+		`<g class="box depth-2 leaf category-a highlight">`,
+		`<g class="box depth-1 leaf category-b">`,
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("exp(%s) is missing in %s", exp, s)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+// DefaultLabelTemplate is label of box with only name of node.
+const DefaultLabelTemplate = "{name}"
+
+// LabelTemplate formats lines of box label from node.
+// Template has fields in braces with optional format after colon, lines are separated by new line or by "\n".
+//
+//	{name}\n{size:bytes} ({share:%})
+//
+// Fields are name, path, size, heat and share, which is size relative to size of root.
+// Heat is value from input before normalization, it is empty for nodes without heat.
+// Formats are bytes (1.2 MB), si (1.2M) and % (12.3%), without format numbers are rounded to two decimals.
+type LabelTemplate struct {
+	lines [][]labelPart
+}
+
+type labelPart struct {
+	text   string
+	field  string
+	format string
+}
+
+var (
+	labelFields  = map[string]bool{"name": true, "path": true, "size": true, "heat": true, "share": true}
+	labelFormats = map[string]bool{"": true, "bytes": true, "si": true, "%": true}
+)
+
+// ParseLabelTemplate checks that fields and formats in template are known.
+func ParseLabelTemplate(template string) (LabelTemplate, error) {
+	var t LabelTemplate
+	template = strings.ReplaceAll(template, `\n`, "\n")
+	for _, line := range strings.Split(template, "\n") {
+		var parts []labelPart
+		for line != "" {
+			start := strings.Index(line, "{")
+			if start < 0 {
+				parts = append(parts, labelPart{text: line})
+				break
+			}
+			end := strings.Index(line[start:], "}")
+			if end < 0 {
+				return LabelTemplate{}, fmt.Errorf("field(%s) is not closed", line[start:])
+			}
+			end += start
+
+			if start > 0 {
+				parts = append(parts, labelPart{text: line[:start]})
+			}
+
+			field, format := line[start+1:end], ""
+			if i := strings.Index(field, ":"); i >= 0 {
+				field, format = field[:i], field[i+1:]
+			}
+			if !labelFields[field] {
+				return LabelTemplate{}, fmt.Errorf("unknown field(%s)", field)
+			}
+			if !labelFormats[format] {
+				return LabelTemplate{}, fmt.Errorf("field(%s) has unknown format(%s)", field, format)
+			}
+			parts = append(parts, labelPart{field: field, format: format})
+
+			line = line[end+1:]
+		}
+		t.lines = append(t.lines, parts)
+	}
+	return t, nil
+}
+
+// Format returns lines of label for node.
+// Empty lines and lines where all fields are empty, like heat of node without heat, are skipped.
+// Zero value of template formats only name.
+func (t LabelTemplate) Format(tree treemap.Tree, node string) []string {
+	if len(t.lines) == 0 {
+		t.lines = [][]labelPart{{{field: "name"}}}
+	}
+
+	var lines []string
+	for _, parts := range t.lines {
+		var b strings.Builder
+		hasFields, hasValues := false, false
+		for _, part := range parts {
+			if part.field == "" {
+				b.WriteString(part.text)
+				continue
+			}
+			v := formatLabelField(tree, node, part.field, part.format)
+			hasFields = true
+			hasValues = hasValues || v != ""
+			b.WriteString(v)
+		}
+		if line := b.String(); strings.TrimSpace(line) != "" && (!hasFields || hasValues) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func formatLabelField(tree treemap.Tree, node string, field, format string) string {
+	if node == treemap.FakeRoot && (field == "name" || field == "path") {
+		// fake root of multiple roots has no name
+		return ""
+	}
+
+	switch field {
+	case "name":
+		return entityToSlash.Replace(tree.Nodes[node].Name)
+	case "path":
+		return entityToSlash.Replace(node)
+	case "size":
+		return formatLabelNumber(nodeSize(tree, node), format)
+	case "heat":
+		if n := tree.Nodes[node]; n.HasHeat {
+			return formatLabelNumber(n.RawHeat, format)
+		}
+		return ""
+	case "share":
+		total := nodeSize(tree, tree.Root)
+		if total <= 0 {
+			return ""
+		}
+		return formatLabelNumber(nodeSize(tree, node)/total, format)
+	}
+	return ""
+}
+
+func formatLabelNumber(v float64, format string) string {
+	switch format {
+	case "bytes":
+		return FormatBytes(v)
+	case "si":
+		return FormatSI(v)
+	case "%":
+		return FormatPercent(v)
+	default:
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	}
+}
+
+// FormatBytes formats number of bytes in units of 1024, e.g. 1.2 MB.
+func FormatBytes(v float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB", "EB"}
+	i := 0
+	for math.Abs(v) >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatFloat(math.Round(v), 'f', -1, 64) + " B"
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + units[i]
+}
+
+// FormatSI formats number with metric suffix, e.g. 1.2M.
+func FormatSI(v float64) string {
+	units := []string{"", "k", "M", "G", "T", "P", "E"}
+	i := 0
+	for math.Abs(v) >= 1000 && i < len(units)-1 {
+		v /= 1000
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + units[i]
+}
+
+// FormatPercent formats fraction as percentage, e.g. 0.123 is 12.3%.
+func FormatPercent(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', 1, 64) + "%"
+}
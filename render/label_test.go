@@ -0,0 +1,121 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestLabelTemplate(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":           {Path: "a", Name: "a", Size: 4096},
+			"a/b&sol;c":   {Path: "a/b&sol;c", Name: "b&sol;c", Size: 1024, Heat: 1, RawHeat: 72.125, HasHeat: true},
+			"a/b&sol;c/d": {Path: "a/b&sol;c/d", Name: "d", Size: 1024},
+			"a/e":         {Path: "a/e", Name: "e", Size: 3072},
+		},
+		To: map[string][]string{
+			"a":         {"a/b&sol;c", "a/e"},
+			"a/b&sol;c": {"a/b&sol;c/d"},
+		},
+		Root: "a",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		node     string
+		exp      []string
+	}{
+		{
+			name:     "when default, then name",
+			template: DefaultLabelTemplate,
+			node:     "a/b&sol;c",
+			exp:      []string{"b/c"},
+		},
+		{
+			name:     "when escaped new line, then multiple lines",
+			template: `{name}\n{size:bytes} ({share:%})`,
+			node:     "a/e",
+			exp:      []string{"e", "3.0 KB (75.0%)"},
+		},
+		{
+			name:     "when heat, then raw heat",
+			template: "{path}\nheat {heat}",
+			node:     "a/b&sol;c",
+			exp:      []string{"a/b/c", "heat 72.13"},
+		},
+		{
+			name:     "when all fields in line are empty, then line is skipped",
+			template: "{name}\nheat {heat}",
+			node:     "a/e",
+			exp:      []string{"e"},
+		},
+		{
+			name:     "when no fields, then text",
+			template: "total\n{size:si}",
+			node:     "a",
+			exp:      []string{"total", "4.1k"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			template, err := ParseLabelTemplate(tc.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := template.Format(tree, tc.node); !reflect.DeepEqual(tc.exp, got) {
+				t.Errorf("exp(%#v) != got(%#v)", tc.exp, got)
+			}
+		})
+	}
+
+	t.Run("when zero value, then name", func(t *testing.T) {
+		if got := (LabelTemplate{}).Format(tree, "a/e"); !reflect.DeepEqual([]string{"e"}, got) {
+			t.Errorf("exp(%#v) != got(%#v)", []string{"e"}, got)
+		}
+	})
+}
+
+func TestParseLabelTemplateError(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string
+	}{
+		{template: "{name", err: "field({name) is not closed"},
+		{template: "{owner}", err: "unknown field(owner)"},
+		{template: "{size:kb}", err: "field(size) has unknown format(kb)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.template, func(t *testing.T) {
+			if _, err := ParseLabelTemplate(tc.template); err == nil || err.Error() != tc.err {
+				t.Errorf("exp(%v) != got(%v)", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestFormatNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(float64) string
+		v    float64
+		exp  string
+	}{
+		{name: "bytes", f: FormatBytes, v: 512, exp: "512 B"},
+		{name: "bytes", f: FormatBytes, v: 1536, exp: "1.5 KB"},
+		{name: "bytes", f: FormatBytes, v: 1.2 * 1024 * 1024, exp: "1.2 MB"},
+		{name: "si", f: FormatSI, v: 999, exp: "999"},
+		{name: "si", f: FormatSI, v: 1234567, exp: "1.2M"},
+		{name: "si", f: FormatSI, v: -2500, exp: "-2.5k"},
+		{name: "percent", f: FormatPercent, v: 0.123, exp: "12.3%"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.f(tc.v); got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+// Linker makes URL of node, boxes with URL are links. Empty URL means no link.
+type Linker interface {
+	URL(tree treemap.Tree, node string) string
+}
+
+// AttributeLinker takes URL from attribute of node, e.g. column of CSV.
+type AttributeLinker struct {
+	Attribute string
+}
+
+func (s AttributeLinker) URL(tree treemap.Tree, node string) string {
+	return tree.Nodes[node].Attributes[s.Attribute]
+}
+
+// TemplateLinker makes URL from template with fields {path} and {name}, e.g. https://github.com/org/repo/tree/main/{path}.
+// Segments of path and name are escaped for URL, slashes between segments are kept.
+type TemplateLinker struct {
+	Template string
+}
+
+func (s TemplateLinker) URL(tree treemap.Tree, node string) string {
+	if node == treemap.FakeRoot {
+		return ""
+	}
+
+	segments := strings.Split(node, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(entityToSlash.Replace(segment))
+	}
+
+	return strings.NewReplacer(
+		"{path}", strings.Join(segments, "/"),
+		"{name}", url.PathEscape(entityToSlash.Replace(tree.Nodes[node].Name)),
+	).Replace(s.Template)
+}
+
+// isSafeURL is false for URLs that can run scripts, like javascript:alert(1).
+// URL comes from input data, and documents are embedded into pages.
+func isSafeURL(u string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto", "ftp":
+		return true
+	default:
+		return false
+	}
+}
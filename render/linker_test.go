@@ -0,0 +1,89 @@
+package render
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestLinker(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"render":              {Path: "render", Name: "render"},
+			"render/a b&sol;c.go": {Path: "render/a b&sol;c.go", Name: "a b&sol;c.go"},
+			"render/svg.go":       {Path: "render/svg.go", Name: "svg.go", Attributes: map[string]string{"url": "https://example.com/svg"}},
+		},
+		Root: "render",
+	}
+
+	tests := []struct {
+		name   string
+		linker Linker
+		node   string
+		exp    string
+	}{
+		{
+			name:   "when template, then path is escaped",
+			linker: TemplateLinker{Template: "https://github.com/org/repo/tree/main/{path}"},
+			node:   "render/a b&sol;c.go",
+			exp:    "https://github.com/org/repo/tree/main/render/a%20b%2Fc.go",
+		},
+		{
+			name:   "when template with name, then name is escaped",
+			linker: TemplateLinker{Template: "https://example.com/search/{name}"},
+			node:   "render/a b&sol;c.go",
+			exp:    "https://example.com/search/a%20b%2Fc.go",
+		},
+		{
+			name:   "when template and fake root, then no link",
+			linker: TemplateLinker{Template: "https://example.com/{path}"},
+			node:   treemap.FakeRoot,
+			exp:    "",
+		},
+		{
+			name:   "when attribute, then value of attribute",
+			linker: AttributeLinker{Attribute: "url"},
+			node:   "render/svg.go",
+			exp:    "https://example.com/svg",
+		},
+		{
+			name:   "when no attribute, then no link",
+			linker: AttributeLinker{Attribute: "url"},
+			node:   "render",
+			exp:    "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.linker.URL(tree, tc.node); got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestBoxSVGLink(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		exp  string
+	}{
+		{name: "when no url, then no link", url: "", exp: ""},
+		{name: "when url, then link with escaped url", url: "https://example.com/?a=1&b=2", exp: `<a xlink:href="https://example.com/?a=1&amp;b=2">`},
+		{name: "when relative url, then link", url: "render/svg.go", exp: `<a xlink:href="render/svg.go">`},
+		{name: "when script url, then no link", url: "javascript:alert(1)", exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := BoxSVG(UIBox{W: 10, H: 10, Color: color.White, BorderColor: color.White, URL: tc.url})
+			if got := strings.Contains(s, "<a "); got != (tc.exp != "") {
+				t.Errorf("link: exp(%v) != got(%v)", tc.exp != "", got)
+			}
+			if tc.exp != "" && !strings.Contains(s, tc.exp) {
+				t.Errorf("exp(%s) is missing in %s", tc.exp, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDFRenderer writes boxes as vector PDF with single page of same size as treemap.
+// Text is in Helvetica, which is one of standard fonts, so fonts are not embedded.
+// Labels fit boxes if builder of boxes has HelveticaTextMeasurer.
+// Runes that are not in Windows-1252 are replaced by question marks.
+// Cushion shading is not rendered.
+type PDFRenderer struct {
+	Theme   Theme // background of page, font and CSS are not used
+	Outline bool  // bookmarks for top-level nodes
+}
+
+// Render returns PDF document, or nil if box is not root.
+func (r PDFRenderer) Render(root UIBox, w, h float64) []byte {
+	var b bytes.Buffer
+	if err := r.RenderTo(&b, root, w, h); err != nil {
+		return nil
+	}
+	return b.Bytes()
+}
+
+func (r PDFRenderer) RenderTo(out io.Writer, root UIBox, w, h float64) error {
+	if !root.IsRoot {
+		return errors.New("box is not root")
+	}
+
+	content := pdfContent{state: -1}
+	// top of page is zero, same as in boxes
+	content.op("1 0 0 -1 0 %s cm", pdfFloat(h))
+	if !r.Theme.Transparent {
+		// text colors are for this background, paper may be of other color
+		background, _ := pdfColor(r.Theme.Background())
+		content.op("%s rg 0 0 %s %s re f", background, pdfFloat(w), pdfFloat(h))
+	}
+
+	var q UIBox
+	que := []UIBox{root}
+	for len(que) > 0 {
+		q, que = que[0], que[1:]
+		que = append(que, q.Children...)
+		content.box(q)
+		if q.URL != "" && isSafeURL(q.URL) && !q.IsInvisible {
+			content.links = append(content.links, q)
+		}
+	}
+	for _, item := range root.Legend {
+		content.box(item.Swatch)
+		content.text(&item.Label)
+	}
+	for i := range root.Annotations {
+		content.text(&root.Annotations[i])
+	}
+
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	zw.Write(content.b.Bytes())
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	d := pdfDocument{}
+	catalog, pages, page, font, contents := d.reserve(), d.reserve(), d.reserve(), d.reserve(), d.reserve()
+
+	var annots []string
+	for _, q := range content.links {
+		annots = append(annots, pdfRef(d.add(fmt.Sprintf(
+			"<< /Type /Annot /Subtype /Link /Rect [%s %s %s %s] /Border [0 0 0] /A << /S /URI /URI %s >> >>",
+			pdfFloat(q.X), pdfFloat(h-q.Y-q.H), pdfFloat(q.X+q.W), pdfFloat(h-q.Y), pdfString(q.URL),
+		))))
+	}
+
+	catalogDict := fmt.Sprintf("<< /Type /Catalog /Pages %s", pdfRef(pages))
+	if top := topLevelBoxes(root); r.Outline && len(top) > 0 {
+		outlines := d.reserve()
+		items := make([]int, len(top))
+		for i := range top {
+			items[i] = d.reserve()
+		}
+		for i, q := range top {
+			item := fmt.Sprintf("<< /Title %s /Parent %s /Dest [%s /XYZ %s %s null]", pdfTextString(entityToSlash.Replace(q.Path)), pdfRef(outlines), pdfRef(page), pdfFloat(q.X), pdfFloat(h-q.Y))
+			if i > 0 {
+				item += " /Prev " + pdfRef(items[i-1])
+			}
+			if i < len(top)-1 {
+				item += " /Next " + pdfRef(items[i+1])
+			}
+			d.set(items[i], item+" >>")
+		}
+		d.set(outlines, fmt.Sprintf("<< /Type /Outlines /First %s /Last %s /Count %d >>", pdfRef(items[0]), pdfRef(items[len(items)-1]), len(items)))
+		catalogDict += fmt.Sprintf(" /Outlines %s /PageMode /UseOutlines", pdfRef(outlines))
+	}
+	d.set(catalog, catalogDict+" >>")
+
+	d.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count 1 >>", pdfRef(page)))
+	d.set(font, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	d.set(contents, fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+
+	var states strings.Builder
+	for i, key := range content.states {
+		states.WriteString(fmt.Sprintf(" /GS%d << /ca %s /CA %s >>", i, key[0], key[1]))
+	}
+	pageDict := fmt.Sprintf(
+		"<< /Type /Page /Parent %s /MediaBox [0 0 %s %s] /Contents %s /Resources << /Font << /F1 %s >> /ExtGState <<%s >> >>",
+		pdfRef(pages), pdfFloat(w), pdfFloat(h), pdfRef(contents), pdfRef(font), states.String(),
+	)
+	if len(annots) > 0 {
+		pageDict += " /Annots [" + strings.Join(annots, " ") + "]"
+	}
+	d.set(page, pageDict+" >>")
+
+	return d.writeTo(out, catalog)
+}
+
+// topLevelBoxes are children of tree root, or tree root if it has no children.
+func topLevelBoxes(root UIBox) []UIBox {
+	if len(root.Children) == 0 {
+		return nil
+	}
+	if top := root.Children[0].Children; len(top) > 0 {
+		return top
+	}
+	return root.Children
+}
+
+// pdfContent is content stream of page.
+type pdfContent struct {
+	b      bytes.Buffer
+	states [][2]string // fill and stroke opacity of graphics states
+	state  int         // current graphics state
+	links  []UIBox
+}
+
+func (c *pdfContent) op(format string, args ...interface{}) {
+	fmt.Fprintf(&c.b, format, args...)
+	c.b.WriteByte('\n')
+}
+
+// opacity sets graphics state with opacity of fill and stroke.
+func (c *pdfContent) opacity(fill, stroke float64) {
+	key := [2]string{pdfFloat(fill), pdfFloat(stroke)}
+	state := -1
+	for i, v := range c.states {
+		if v == key {
+			state = i
+			break
+		}
+	}
+	if state < 0 {
+		state = len(c.states)
+		c.states = append(c.states, key)
+	}
+	if state != c.state {
+		c.op("/GS%d gs", state)
+		c.state = state
+	}
+}
+
+func (c *pdfContent) box(q UIBox) {
+	if q.IsInvisible {
+		return
+	}
+
+	borderWidth := q.BorderWidth
+	if borderWidth <= 0 {
+		borderWidth = 1
+	}
+
+	fill, fillOpacity := pdfColor(colorOr(q.Color, color.White))
+	stroke, strokeOpacity := pdfColor(colorOr(q.BorderColor, color.White))
+	c.opacity(fillOpacity, strokeOpacity)
+	c.op("%s rg %s RG %s w", fill, stroke, pdfFloat(borderWidth))
+	c.op("%s %s %s %s re B", pdfFloat(q.X), pdfFloat(q.Y), pdfFloat(q.W), pdfFloat(q.H))
+
+	c.text(q.Title)
+	for i := range q.Lines {
+		c.text(&q.Lines[i])
+	}
+}
+
+// text is flipped back, since page is flipped
+func (c *pdfContent) text(t *UIText) {
+	if t == nil {
+		return
+	}
+
+	s := pdfString(string(winAnsi(t.Text)))
+	size := pdfFloat(float64(fontSize) * t.Scale)
+	matrix := fmt.Sprintf("1 0 0 -1 %s %s Tm", pdfFloat(t.X), pdfFloat(t.Y+t.H))
+
+	if t.Outline != nil {
+		// halo is stroke under text
+		outline, opacity := pdfColor(t.Outline)
+		c.opacity(1, opacity)
+		c.op("BT /F1 %s Tf %s 1 Tr %s RG %s w 1 j %s Tj ET", size, matrix, outline, pdfFloat(3*t.Scale), s)
+	}
+
+	fill, opacity := pdfColor(colorOr(t.Color, color.Black))
+	c.opacity(opacity, 1)
+	c.op("BT /F1 %s Tf %s 0 Tr %s rg %s Tj ET", size, matrix, fill, s)
+}
+
+// pdfDocument has objects by number starting from one, references to objects can be made before objects are set.
+type pdfDocument struct {
+	objects []string
+}
+
+// reserve returns number of object that is set later.
+func (d *pdfDocument) reserve() int {
+	d.objects = append(d.objects, "")
+	return len(d.objects)
+}
+
+func (d *pdfDocument) add(object string) int {
+	n := d.reserve()
+	d.objects[n-1] = object
+	return n
+}
+
+func (d *pdfDocument) set(n int, object string) {
+	d.objects[n-1] = object
+}
+
+// pdfRef is reference to object by its number.
+func pdfRef(n int) string {
+	return strconv.Itoa(n) + " 0 R"
+}
+
+// writeTo writes objects and cross-reference table with their offsets.
+func (d *pdfDocument) writeTo(out io.Writer, catalog int) error {
+	w := &countingWriter{w: bufio.NewWriter(out)}
+
+	io.WriteString(w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int64, len(d.objects))
+	for i, object := range d.objects {
+		offsets[i] = w.n
+		fmt.Fprintf(w, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := w.n
+	fmt.Fprintf(w, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(w, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(w, "trailer\n<< /Size %d /Root %s >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, pdfRef(catalog), xref)
+
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return n, err
+}
+
+// pdfColor is color without alpha premultiplication and its opacity.
+func pdfColor(c color.Color) (string, float64) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("%s %s %s", pdfFloat(float64(n.R)/255), pdfFloat(float64(n.G)/255), pdfFloat(float64(n.B)/255)), float64(n.A) / 255
+}
+
+func pdfFloat(v float64) string {
+	return string(appendCompactFloat(nil, v, 3))
+}
+
+var pdfStringEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`, "\r", `\r`, "\n", `\n`)
+
+// pdfString is literal string of bytes.
+func pdfString(s string) string {
+	return "(" + pdfStringEscaper.Replace(s) + ")"
+}
+
+// pdfTextString is text for viewer, such as title of bookmark, non-ASCII text is in UTF-16 with byte order mark.
+func pdfTextString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r < ' ' || r > '~' {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return pdfString(s)
+	}
+
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, v := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", v)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// winAnsiSpecial are runes of Windows-1252 that are not same as in Latin-1.
+var winAnsiSpecial = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+	'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+	'–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsi encodes text for standard fonts, runes that are not in encoding are question marks.
+func winAnsi(text string) []byte {
+	b := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+			b = append(b, byte(r))
+		case winAnsiSpecial[r] != 0:
+			b = append(b, winAnsiSpecial[r])
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package render
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestPDFRenderer(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":              {Path: "a", Name: "a", Size: 3},
+			"a/Zürich":       {Path: "a/Zürich", Name: "Zürich", Size: 2},
+			"a/b (1)":        {Path: "a/b (1)", Name: "b (1)", Size: 1},
+			"a/b (1)/東京":     {Path: "a/b (1)/東京", Name: "東京", Size: 1},
+			"a/Zürich/north": {Path: "a/Zürich/north", Name: "north", Size: 2},
+		},
+		To: map[string][]string{
+			"a":        {"a/Zürich", "a/b (1)"},
+			"a/b (1)":  {"a/b (1)/東京"},
+			"a/Zürich": {"a/Zürich/north"},
+		},
+		Root: "a",
+	}
+	builder := UITreeMapBuilder{
+		Colorer:     NoneColorer{},
+		BorderColor: color.White,
+		Linker:      TemplateLinker{Template: "https://example.com/{path}"},
+	}
+	spec := builder.NewUITreeMap(tree, 400, 300, 1, 1, 8)
+
+	doc := PDFRenderer{Outline: true}.Render(spec, 400, 300)
+
+	t.Run("objects are at offsets from cross-reference table", func(t *testing.T) {
+		m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(doc)
+		if m == nil {
+			t.Fatal("no startxref")
+		}
+		xref, _ := strconv.Atoi(string(m[1]))
+		lines := strings.Split(string(doc[xref:]), "\n")
+		var n int
+		fmt.Sscanf(lines[1], "0 %d", &n)
+		for i := 1; i < n; i++ {
+			offset, _ := strconv.Atoi(lines[2+i][:10])
+			if exp := fmt.Sprintf("%d 0 obj", i); !bytes.HasPrefix(doc[offset:], []byte(exp)) {
+				t.Errorf("object(%d) is not at offset(%d)", i, offset)
+			}
+		}
+	})
+
+	t.Run("content has boxes and text", func(t *testing.T) {
+		content := pdfContentStream(t, doc)
+		for _, exp := range []string{"1 0 0 -1 0 300 cm", "1 1 1 rg 0 0 400 300 re f", "re B", "(Z\xfcrich) Tj", `(b \(1\)) Tj`} {
+			if !bytes.Contains(content, []byte(exp)) {
+				t.Errorf("exp(%s) is missing in %s", exp, content)
+			}
+		}
+	})
+
+	for _, exp := range []string{
+		"/BaseFont /Helvetica",
+		"/Title <FEFF0061002F005A00FC0072006900630068> /Parent",
+		`/Title (a/b \(1\)) /Parent`,
+		"/URI (https://example.com/a/b%20%281%29)",
+	} {
+		if !bytes.Contains(doc, []byte(exp)) {
+			t.Errorf("exp(%s) is missing", exp)
+		}
+	}
+
+	if doc := (PDFRenderer{}).Render(spec, 400, 300); bytes.Contains(doc, []byte("/Outlines")) {
+		t.Errorf("outline is not expected")
+	}
+}
+
+func TestPDFRendererBackground(t *testing.T) {
+	spec := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(makeWideTree(1, 3), 400, 300, 1, 1, 8)
+
+	tests := []struct {
+		name  string
+		theme Theme
+		exp   string
+	}{
+		{name: "when light, then white page", theme: Theme{}, exp: "1 1 1 rg 0 0 400 300 re f"},
+		{name: "when dark, then dark page", theme: Theme{Dark: true}, exp: "0.051 0.067 0.09 rg 0 0 400 300 re f"},
+		{name: "when transparent, then no background", theme: Theme{Dark: true, Transparent: true}, exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			content := pdfContentStream(t, PDFRenderer{Theme: tc.theme}.Render(spec, 400, 300))
+			if tc.exp == "" {
+				if bytes.Contains(content, []byte("re f\n")) {
+					t.Errorf("background is not expected in %s", content)
+				}
+				return
+			}
+			if !bytes.Contains(content, []byte(tc.exp)) {
+				t.Errorf("exp(%s) is missing in %s", tc.exp, content)
+			}
+		})
+	}
+}
+
+// pdfContentStream is decompressed content of page.
+func pdfContentStream(t *testing.T, doc []byte) []byte {
+	m := regexp.MustCompile(`(?s)/Length (\d+) /Filter /FlateDecode >>\nstream\n(.*)\nendstream`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("no content stream")
+	}
+	if length, _ := strconv.Atoi(string(m[1])); length != len(m[2]) {
+		t.Errorf("length: exp(%d) != got(%d)", len(m[2]), length)
+	}
+	r, err := zlib.NewReader(bytes.NewReader(m[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestWinAnsi(t *testing.T) {
+	tests := []struct {
+		text string
+		exp  []byte
+	}{
+		{text: "abc", exp: []byte("abc")},
+		{text: "Zürich…", exp: []byte("Z\xfcrich\x85")},
+		{text: "東京", exp: []byte("??")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			if got := winAnsi(tc.text); !bytes.Equal(tc.exp, got) {
+				t.Errorf("exp(%q) != got(%q)", tc.exp, got)
+			}
+		})
+	}
+}
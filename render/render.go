@@ -1,10 +1,14 @@
 package render
 
 import (
+	"fmt"
 	"image/color"
+	"math"
+	"sort"
 	"strings"
-	"unicode/utf8"
+	"time"
 
+	"github.com/lucasb-eyer/go-colorful"
 	"github.com/nikolaydubina/treemap"
 	"github.com/nikolaydubina/treemap/layout"
 )
@@ -16,8 +20,20 @@ const (
 	tooSmallBoxHeight    float64 = 5
 	tooSmallBoxWidth     float64 = 5
 	textMarginH          float64 = 2
+	legendMarginTop      float64 = 8
+	legendItemMargin     float64 = 12
+	legendSwatchMargin   float64 = 4
+	legendRowHeight      float64 = 16
+	maxSummaryItems      int     = 10
+	annotationMargin     float64 = 8
+	titleScale           float64 = 1.5
+	subtitleScale        float64 = 1
+	footerScale          float64 = 0.85
 )
 
+// TimestampLayout is format of timestamp in footer.
+const TimestampLayout = "2006-01-02 15:04 MST"
+
 // entityToSlash has HTML entities to strings mapping
 var entityToSlash = strings.NewReplacer(
 	"&sol;", "/",
@@ -25,18 +41,21 @@ var entityToSlash = strings.NewReplacer(
 
 // UIText is spec on how to render text.
 type UIText struct {
-	Text  string
-	X     float64
-	Y     float64
-	H     float64
-	W     float64
-	Scale float64
-	Color color.Color
+	Text    string
+	X       float64
+	Y       float64
+	H       float64
+	W       float64
+	Scale   float64
+	Color   color.Color
+	Outline color.Color // halo around text, if present
+	Class   string      // name of text for styling, e.g. title
 }
 
 // UIBox is spec on how to render a box. Could be Root.
 type UIBox struct {
 	Title       *UIText
+	Lines       []UIText // lines of label after title
 	X           float64
 	Y           float64
 	W           float64
@@ -46,6 +65,40 @@ type UIBox struct {
 	IsRoot      bool
 	Color       color.Color
 	BorderColor color.Color
+	BorderWidth float64        // default is 1
+	Depth       int            // depth of node in tree, tree root is zero
+	IsLeaf      bool           // node has no children in tree
+	Category    string         // category of node, if colorer has categories
+	URL         string         // box is link, if present
+	Highlighted bool           // border is from highlighter of colorer
+	Description string         // for screen readers and tooltips, summary of treemap in root, path, values and attributes in other boxes
+	Path        string         // path of node, slashes in names are escaped
+	Cushion     bool           // shade box as cushion, in root means that some boxes are cushions
+	Legend      []UILegendItem // only for root
+	Annotations []UIText       // title, subtitle and footer, only for root
+}
+
+// UILegendItem is spec on how to render legend entry, it is color swatch with label to the right.
+type UILegendItem struct {
+	Swatch UIBox
+	Label  UIText
+}
+
+// LegendItem is meaning of single color.
+type LegendItem struct {
+	Label string
+	Color color.Color
+}
+
+// Legender is Colorer that can explain its colors.
+type Legender interface {
+	Legend() []LegendItem
+}
+
+// Categorizer is Colorer that colors nodes by category.
+// Categories are kept in boxes, so that renderers can style them.
+type Categorizer interface {
+	Category(tree treemap.Tree, node string) string
 }
 
 func (f UIBox) IsEmpty() bool {
@@ -57,9 +110,64 @@ type Colorer interface {
 	ColorText(tree treemap.Tree, node string) color.Color
 }
 
+// DepthStyle varies style of boxes by their depth in tree, so that levels are distinguishable.
+// Values by depth start from tree root, last value is used for deeper boxes.
+type DepthStyle struct {
+	BorderWidths []float64
+	BorderColors []color.Color
+	Shade        float64 // each level is darker by this fraction, transparent boxes get this much black
+}
+
+func (d DepthStyle) borderWidth(depth int) float64 {
+	if len(d.BorderWidths) == 0 {
+		return 0
+	}
+	if depth >= len(d.BorderWidths) {
+		return d.BorderWidths[len(d.BorderWidths)-1]
+	}
+	return d.BorderWidths[depth]
+}
+
+func (d DepthStyle) borderColor(depth int, c color.Color) color.Color {
+	if len(d.BorderColors) == 0 {
+		return c
+	}
+	if depth >= len(d.BorderColors) {
+		return d.BorderColors[len(d.BorderColors)-1]
+	}
+	return d.BorderColors[depth]
+}
+
+// shade makes color darker with each level, transparent colors are layered so they get same shade each level.
+func (d DepthStyle) shade(depth int, c color.Color) color.Color {
+	if d.Shade <= 0 || depth == 0 {
+		return c
+	}
+	col, ok := colorful.MakeColor(c)
+	if !ok {
+		return color.NRGBA{A: uint8(255 * math.Min(d.Shade, 1))}
+	}
+	h, cc, l := col.Hcl()
+	return colorful.Hcl(h, cc, l*math.Pow(1-d.Shade, float64(depth))).Clamped()
+}
+
 type UITreeMapBuilder struct {
-	Colorer     Colorer
-	BorderColor color.Color
+	Colorer         Colorer
+	BorderColor     color.Color
+	Legend          []LegendItem // if present, then legend is placed below treemap, default is legend of colorer
+	MinTextContrast float64      // if text has lower WCAG contrast ratio with box, then text gets outline
+	DepthStyle      DepthStyle
+	Cushion         bool          // shade boxes as cushions, nested cushions add up to show hierarchy
+	Label           LabelTemplate // lines of text in box, default is name
+	TextOverflow    TextOverflow  // what to do with lines wider than box
+	MinFontSize     float64       // lines that have to be scaled to smaller font are dropped with all lines after
+	TextMeasurer    TextMeasurer  // default is HelveticaTextMeasurer, RuneCountTextMeasurer is estimate for fonts without glyph widths
+	Background      color.Color   // color under boxes, default is white, text is readable on boxes composed over it
+	Title           string        // above treemap
+	Subtitle        string        // below title
+	Footer          string        // below treemap and legend, e.g. source of data
+	Timestamp       time.Time     // if set, then added to footer
+	Linker          Linker        // if set, then boxes are links
 }
 
 func (s UITreeMapBuilder) NewUITreeMap(tree treemap.Tree, w, h, margin, padding, paddingRoot float64) UIBox {
@@ -70,16 +178,56 @@ func (s UITreeMapBuilder) NewUITreeMap(tree treemap.Tree, w, h, margin, padding,
 		H:           h - (2 * paddingRoot),
 		IsInvisible: true,
 		IsRoot:      true,
+		Cushion:     s.Cushion,
+		Description: summary(tree),
+	}
+
+	// title and subtitle take top of root
+	for _, a := range []UIText{{Text: s.Title, Class: "title", Scale: titleScale}, {Text: s.Subtitle, Class: "subtitle", Scale: subtitleScale}} {
+		if a.Text == "" {
+			continue
+		}
+		a = s.newUIAnnotation(a, t.X, t.Y, t.W)
+		t.Annotations = append(t.Annotations, a)
+		t.Y += a.H + annotationMargin
+		t.H -= a.H + annotationMargin
+	}
+
+	// footer takes bottom of root, legend is above it
+	if footer := s.footer(); footer != "" {
+		a := s.newUIAnnotation(UIText{Text: footer, Class: "footer", Scale: footerScale}, t.X, 0, t.W)
+		t.H -= a.H + annotationMargin
+		a.Y = t.Y + t.H + annotationMargin
+		t.Annotations = append(t.Annotations, a)
+	}
+
+	if l, ok := s.Colorer.(Legender); ok && len(s.Legend) == 0 {
+		s.Legend = l.Legend()
+	}
+	if len(s.Legend) > 0 {
+		// legend takes bottom of root
+		t.Legend = s.newUILegend(t.X, t.W)
+		legendHeight := t.Legend[len(t.Legend)-1].Swatch.Y + legendRowHeight
+		t.H -= legendHeight + legendMarginTop
+		for i := range t.Legend {
+			t.Legend[i].Swatch.Y += t.Y + t.H + legendMarginTop
+			t.Legend[i].Label.Y += t.Y + t.H + legendMarginTop
+		}
 	}
 
 	t.Children = []UIBox{
-		s.NewUIBox(tree.Root, tree, t.X, t.Y, t.W, t.H, margin, padding),
+		s.newUIBox(tree.Root, tree, t.X, t.Y, t.W, t.H, margin, padding, 0),
 	}
 
 	return t
 }
 
+// NewUIBox makes box for node as if it is at top of tree.
 func (s UITreeMapBuilder) NewUIBox(node string, tree treemap.Tree, x, y, w, h, margin float64, padding float64) UIBox {
+	return s.newUIBox(node, tree, x, y, w, h, margin, padding, 0)
+}
+
+func (s UITreeMapBuilder) newUIBox(node string, tree treemap.Tree, x, y, w, h, margin float64, padding float64, depth int) UIBox {
 	if (w <= (2 * padding)) || (h <= (2 * padding)) || w < tooSmallBoxWidth || h < tooSmallBoxHeight {
 		// too small, do not render
 		return UIBox{}
@@ -90,27 +238,59 @@ func (s UITreeMapBuilder) NewUIBox(node string, tree treemap.Tree, x, y, w, h, m
 		Y:           y + margin,
 		W:           w - (2 * margin),
 		H:           h - (2 * margin),
-		Color:       s.Colorer.ColorBox(tree, node),
-		BorderColor: s.BorderColor,
+		Color:       s.DepthStyle.shade(depth, s.Colorer.ColorBox(tree, node)),
+		BorderColor: s.DepthStyle.borderColor(depth, s.BorderColor),
+		BorderWidth: s.DepthStyle.borderWidth(depth),
+		Depth:       depth,
+		IsLeaf:      len(tree.To[node]) == 0,
+		Cushion:     s.Cushion,
+		Description: describe(tree, node),
+		Path:        node,
+	}
+	if c, ok := s.Colorer.(Categorizer); ok {
+		t.Category = c.Category(tree, node)
+	}
+	if s.Linker != nil {
+		t.URL = s.Linker.URL(tree, node)
+	}
+	if h, ok := s.Colorer.(Highlighter); ok {
+		if border, width, ok := h.Highlight(tree, node); ok {
+			t.BorderColor, t.BorderWidth, t.Highlighted = border, width, true
+		}
 	}
 
 	var textHeight float64
-	if title := entityToSlash.Replace(tree.Nodes[node].Name); title != "" && title != "some-secret-string" {
-		// fit text
+	if node != treemap.FakeRoot {
+		// fit each line of label separately
 		// margin here and padding to account for children
 		w := t.W - (2 * padding) - (2 * margin)
 		h := t.H - (2 * padding) - (2 * margin) - (2 * textMarginH)
-		if scale, th := fitText(title, fontSize, w); scale > 0 && th > 0 && th < h {
-			textHeight = th
+		var lines []string
+		for _, line := range s.Label.Format(tree, node) {
+			lines = append(lines, s.TextOverflow.Lines(s.textMeasurer(), line, fontSize, w)...)
+		}
+		for _, line := range lines {
+			scale, th := fitText(s.textMeasurer(), line, fontSize, w)
+			if scale <= 0 || th <= 0 || (textHeight+th) >= h || (scale*float64(fontSize)) < s.MinFontSize {
+				break
+			}
 			// if enough space for text, then add
-			t.Title = &UIText{
-				Text:  title,
+			text := UIText{
+				Text:  line,
 				X:     t.X + padding + margin,
-				Y:     t.Y + padding + textMarginH,
+				Y:     t.Y + padding + textMarginH + textHeight,
 				W:     w,
-				H:     textHeight,
+				H:     th,
 				Scale: scale,
-				Color: s.Colorer.ColorText(tree, node),
+				Color: s.textColor(tree, node, t.Color),
+			}
+			text.Outline = s.textOutline(text.Color, composeOver(t.Color, s.background()))
+			textHeight += th
+
+			if t.Title == nil {
+				t.Title = &text
+			} else {
+				t.Lines = append(t.Lines, text)
 			}
 		}
 	}
@@ -136,7 +316,7 @@ func (s UITreeMapBuilder) NewUIBox(node string, tree treemap.Tree, x, y, w, h, m
 		if boxes[i] == layout.NilBox {
 			continue
 		}
-		box := s.NewUIBox(
+		box := s.newUIBox(
 			toPath,
 			tree,
 			boxes[i].X,
@@ -145,6 +325,7 @@ func (s UITreeMapBuilder) NewUIBox(node string, tree treemap.Tree, x, y, w, h, m
 			boxes[i].H,
 			margin,
 			padding,
+			depth+1,
 		)
 		if box.IsEmpty() {
 			continue
@@ -155,6 +336,162 @@ func (s UITreeMapBuilder) NewUIBox(node string, tree treemap.Tree, x, y, w, h, m
 	return t
 }
 
+func (s UITreeMapBuilder) background() color.Color {
+	if s.Background == nil {
+		return color.White
+	}
+	return s.Background
+}
+
+// textColor is from colorer, unless colorer does not know what is under text,
+// that is when box is shaded or when background shows through box that is not opaque.
+func (s UITreeMapBuilder) textColor(tree treemap.Tree, node string, box color.Color) color.Color {
+	if box == nil {
+		return s.Colorer.ColorText(tree, node)
+	}
+	if _, _, _, a := box.RGBA(); s.DepthStyle.Shade > 0 || a < 0xffff {
+		return ContrastTextColor(composeOver(box, s.background()))
+	}
+	return s.Colorer.ColorText(tree, node)
+}
+
+func (s UITreeMapBuilder) textMeasurer() TextMeasurer {
+	if s.TextMeasurer == nil {
+		return HelveticaTextMeasurer{}
+	}
+	return s.TextMeasurer
+}
+
+// textOutline is color of outline for text to be readable on box, or nil if text is readable as is.
+func (s UITreeMapBuilder) textOutline(text, box color.Color) color.Color {
+	if s.MinTextContrast <= 0 || ContrastRatio(text, box) >= s.MinTextContrast {
+		return nil
+	}
+	return ContrastTextColor(text)
+}
+
+// newUILegend places legend items in rows from left to right starting at y zero.
+func (s UITreeMapBuilder) newUILegend(x, w float64) []UILegendItem {
+	swatchSize := float64(fontSize)
+	items := make([]UILegendItem, 0, len(s.Legend))
+
+	var offsetX, offsetY float64
+	for _, item := range s.Legend {
+		labelW := s.textMeasurer().TextWidth(item.Label, float64(fontSize))
+		itemW := swatchSize + legendSwatchMargin + labelW
+		if offsetX > 0 && offsetX+itemW > w {
+			offsetX = 0
+			offsetY += legendRowHeight
+		}
+
+		labelH := textHeight(item.Label, float64(fontSize))
+		items = append(items, UILegendItem{
+			Swatch: UIBox{
+				X:           x + offsetX,
+				Y:           offsetY,
+				W:           swatchSize,
+				H:           swatchSize,
+				Color:       item.Color,
+				BorderColor: s.BorderColor,
+			},
+			Label: UIText{
+				Text:  item.Label,
+				X:     x + offsetX + swatchSize + legendSwatchMargin,
+				Y:     offsetY + ((swatchSize - labelH) / 2),
+				W:     labelW,
+				H:     labelH,
+				Scale: 1,
+				Color: ContrastTextColor(s.background()),
+			},
+		})
+
+		offsetX += itemW + legendItemMargin
+	}
+
+	return items
+}
+
+// newUIAnnotation scales down text to fit width.
+func (s UITreeMapBuilder) newUIAnnotation(text UIText, x, y, w float64) UIText {
+	if tw := s.textMeasurer().TextWidth(text.Text, float64(fontSize)); (tw * text.Scale) > w {
+		text.Scale = w / tw
+	}
+	text.X = x
+	text.Y = y
+	text.W = w
+	text.H = textHeight(text.Text, float64(fontSize)) * text.Scale
+	text.Color = ContrastTextColor(s.background())
+	return text
+}
+
+func (s UITreeMapBuilder) footer() string {
+	switch {
+	case s.Timestamp.IsZero():
+		return s.Footer
+	case s.Footer == "":
+		return s.Timestamp.Format(TimestampLayout)
+	default:
+		return s.Footer + " · " + s.Timestamp.Format(TimestampLayout)
+	}
+}
+
+// summary describes treemap by largest nodes at top level.
+func summary(tree treemap.Tree) string {
+	top := tree.To[tree.Root]
+	if len(top) == 0 {
+		top = []string{tree.Root}
+	}
+	top = append([]string(nil), top...)
+	sort.SliceStable(top, func(i, j int) bool { return nodeSize(tree, top[i]) > nodeSize(tree, top[j]) })
+
+	total := nodeSize(tree, tree.Root)
+	items := make([]string, 0, maxSummaryItems+1)
+	for i, node := range top {
+		if i == maxSummaryItems {
+			items = append(items, fmt.Sprintf("and %d more", len(top)-maxSummaryItems))
+			break
+		}
+		item := entityToSlash.Replace(tree.Nodes[node].Name)
+		if item == "" {
+			item = entityToSlash.Replace(node)
+		}
+		if total > 0 {
+			item += " " + FormatPercent(nodeSize(tree, node)/total)
+		}
+		items = append(items, item)
+	}
+
+	s := fmt.Sprintf("Treemap of %d nodes", len(tree.Nodes))
+	if name := tree.Nodes[tree.Root].Name; tree.Root != treemap.FakeRoot && name != "" {
+		s += " in " + entityToSlash.Replace(name)
+	}
+	return s + ", largest at top level: " + strings.Join(items, ", ")
+}
+
+// describe has path, values and attributes of node.
+func describe(tree treemap.Tree, node string) string {
+	if node == treemap.FakeRoot {
+		return ""
+	}
+	s := entityToSlash.Replace(node) + ", size " + formatLabelNumber(nodeSize(tree, node), "")
+	if total := nodeSize(tree, tree.Root); total > 0 {
+		s += ", " + FormatPercent(nodeSize(tree, node)/total) + " of total"
+	}
+	n := tree.Nodes[node]
+	if n.HasHeat {
+		s += ", heat " + formatLabelNumber(n.RawHeat, "")
+	}
+	names := make([]string, 0, len(n.Attributes))
+	for name := range n.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s += ", " + name + ": " + n.Attributes[name]
+	}
+	return s
+}
+
 func nodeSize(tree treemap.Tree, node string) float64 {
 	if n, ok := tree.Nodes[node]; ok {
 		return n.Size
@@ -167,8 +504,8 @@ func nodeSize(tree treemap.Tree, node string) float64 {
 }
 
 // compute scale to fit worst dimension
-func fitText(text string, fontSize int, W float64) (scale float64, h float64) {
-	w := textWidth(text, float64(fontSize))
+func fitText(m TextMeasurer, text string, fontSize int, W float64) (scale float64, h float64) {
+	w := m.TextWidth(text, float64(fontSize))
 	h = textHeight(text, float64(fontSize))
 
 	scale = 1.0
@@ -184,10 +521,6 @@ func fitText(text string, fontSize int, W float64) (scale float64, h float64) {
 	return scale, h
 }
 
-func textWidth(text string, fontSize float64) float64 {
-	return fontSize * float64(utf8.RuneCountInString(text)) * textWidthMultiplier
-}
-
 func textHeight(text string, fontSize float64) float64 {
 	return fontSize * textHeightMultiplier
 }
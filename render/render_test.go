@@ -1,8 +1,16 @@
 package render
 
 import (
+	"bytes"
+	"image/color"
 	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/nikolaydubina/treemap"
+	"github.com/nikolaydubina/treemap/parser"
 )
 
 func TestTextWidth(t *testing.T) {
@@ -28,3 +36,164 @@ func TestTextWidth(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderDeterministic(t *testing.T) {
+	in := `Africa/Algeria,33333216,72
+Africa/Angola,12420476,42
+Americas/Argentina,40301927,75
+Americas/Bolivia,9119152,65
+Asia/Japan,127467972,82
+Europe/France,61083916,80
+Oceania/Australia,20434176,81
+`
+	tests := []struct {
+		name    string
+		colorer func(tree treemap.Tree) Colorer
+	}{
+		{
+			name: "when tree hue colorer, then same",
+			colorer: func(tree treemap.Tree) Colorer {
+				return TreeHueColorer{Hues: TreeHues(tree, 0), C: 0.5, L: 0.5, DeltaH: 10, DeltaC: 0.3, DeltaL: 0.1}
+			},
+		},
+		{
+			name: "when fast tree hue colorer, then same",
+			colorer: func(tree treemap.Tree) Colorer {
+				return NewFastTreeHueColorer(tree, 0, 0.5, 0.5)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			render := func() []byte {
+				tree, err := parser.CSVTreeParser{}.ParseString(in)
+				if err != nil {
+					t.Fatal(err)
+				}
+				treemap.SetNamesFromPaths(tree)
+				treemap.SumSizeImputer{EmptyLeafSize: 1}.ImputeSize(*tree)
+				tree.NormalizeHeat()
+
+				uiBuilder := UITreeMapBuilder{
+					Colorer:     tc.colorer(*tree),
+					BorderColor: color.White,
+				}
+				spec := uiBuilder.NewUITreeMap(*tree, 1028, 640, 4, 4, 32)
+				return SVGRenderer{}.Render(spec, 1028, 640)
+			}
+
+			exp := render()
+			for i := 0; i < 10; i++ {
+				if got := render(); !bytes.Equal(exp, got) {
+					t.Fatalf("render(%d) is different", i)
+				}
+			}
+		})
+	}
+}
+
+func TestDepthStyle(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":     {Path: "a", Size: 1},
+			"a/b":   {Path: "a/b", Size: 1},
+			"a/b/c": {Path: "a/b/c", Size: 1},
+		},
+		To: map[string][]string{
+			"a":   {"a/b"},
+			"a/b": {"a/b/c"},
+		},
+		Root: "a",
+	}
+
+	uiBuilder := UITreeMapBuilder{
+		Colorer:     NoneColorer{},
+		BorderColor: color.White,
+		DepthStyle: DepthStyle{
+			BorderWidths: []float64{3, 1},
+			BorderColors: []color.Color{color.Black},
+			Shade:        0.1,
+		},
+		Cushion: true,
+	}
+	spec := uiBuilder.NewUITreeMap(tree, 1028, 640, 4, 4, 32)
+
+	var boxes []UIBox
+	for q := spec.Children; len(q) > 0; q = q[0].Children {
+		boxes = append(boxes, q[0])
+	}
+	if len(boxes) != 3 {
+		t.Fatalf("exp(3) != got(%d) boxes", len(boxes))
+	}
+
+	for depth, box := range boxes {
+		if box.Depth != depth {
+			t.Errorf("exp(%d) != got(%d)", depth, box.Depth)
+		}
+		if box.BorderColor != color.Black {
+			t.Errorf("depth(%d) border color: exp(%v) != got(%v)", depth, color.Black, box.BorderColor)
+		}
+		if !box.Cushion {
+			t.Errorf("depth(%d) is not cushion", depth)
+		}
+	}
+
+	for depth, exp := range []float64{3, 1, 1} {
+		if boxes[depth].BorderWidth != exp {
+			t.Errorf("depth(%d) border width: exp(%v) != got(%v)", depth, exp, boxes[depth].BorderWidth)
+		}
+	}
+
+	if _, _, _, a := boxes[0].Color.RGBA(); a != 0 {
+		t.Errorf("root box should stay transparent, got alpha(%d)", a)
+	}
+	for _, box := range boxes[1:] {
+		if _, _, _, a := box.Color.RGBA(); a == 0 {
+			t.Errorf("depth(%d) transparent box should be shaded", box.Depth)
+		}
+	}
+
+	if s := string(SVGRenderer{}.Render(spec, 1028, 640)); !strings.Contains(s, `<radialGradient id="cushion"`) {
+		t.Errorf("cushion gradient is missing")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{"a": {Path: "a", Name: "a", Size: 1}},
+		Root:  "a",
+	}
+
+	plain := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(tree, 400, 300, 1, 1, 8)
+
+	builder := UITreeMapBuilder{
+		Colorer:     NoneColorer{},
+		BorderColor: color.White,
+		Title:       "Population",
+		Subtitle:    "by country",
+		Footer:      "Source: Gapminder",
+		Timestamp:   time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC),
+	}
+	spec := builder.NewUITreeMap(tree, 400, 300, 1, 1, 8)
+
+	var got []string
+	for _, a := range spec.Annotations {
+		got = append(got, a.Class+": "+a.Text)
+	}
+	exp := []string{"title: Population", "subtitle: by country", "footer: Source: Gapminder · 2021-03-04 05:06 UTC"}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("exp(%#v) != got(%#v)", exp, got)
+	}
+
+	if spec.Y <= plain.Y || (spec.Y+spec.H) >= (plain.Y+plain.H) {
+		t.Errorf("treemap area should be shrunk: exp inside (%v, %v) got(%v, %v)", plain.Y, plain.Y+plain.H, spec.Y, spec.Y+spec.H)
+	}
+	for _, a := range spec.Annotations {
+		if a.Class == "footer" && a.Y < (spec.Y+spec.H) {
+			t.Errorf("footer(%v) overlaps treemap(%v)", a.Y, spec.Y+spec.H)
+		}
+		if a.Class != "footer" && (a.Y+a.H) > spec.Y {
+			t.Errorf("%s(%v) overlaps treemap(%v)", a.Class, a.Y+a.H, spec.Y)
+		}
+	}
+}
@@ -1,11 +1,21 @@
 package render
 
 import (
-	"fmt"
+	"bufio"
+	"bytes"
+	"errors"
 	"image/color"
+	"io"
+	"strconv"
 	"strings"
 )
 
+// precision of floats in SVG, hundredths of pixel are not visible
+const (
+	coordPrecision = 2
+	scalePrecision = 3
+)
+
 var xmlEscaper = strings.NewReplacer(
 	"&", "&amp;",
 	"<", "&lt;",
@@ -14,104 +24,298 @@ var xmlEscaper = strings.NewReplacer(
 	"'", "&apos;",
 )
 
-type SVGRenderer struct{}
+// cushionGradientSVG is highlight at top left and shadow at bottom right of box.
+// Boxes are nested, so gradients add up similar to cushion treemaps by Van Wijk and van de Wetering.
+const cushionGradientSVG = `<defs><radialGradient id="cushion" cx="0.35" cy="0.3" r="0.85">` +
+	`<stop offset="0" stop-color="white" stop-opacity="0.35"/>` +
+	`<stop offset="0.55" stop-color="white" stop-opacity="0"/>` +
+	`<stop offset="1" stop-color="black" stop-opacity="0.3"/>` +
+	`</radialGradient></defs>` + "\n"
 
+type SVGRenderer struct {
+	Theme Theme
+}
+
+// Render returns SVG document, or nil if box is not root.
 func (r SVGRenderer) Render(root UIBox, w, h float64) []byte {
-	if !root.IsRoot {
+	var b bytes.Buffer
+	if err := r.RenderTo(&b, root, w, h); err != nil {
 		return nil
 	}
+	return b.Bytes()
+}
+
+// RenderTo writes SVG document while traversing boxes, so that document is never whole in memory.
+func (r SVGRenderer) RenderTo(out io.Writer, root UIBox, w, h float64) error {
+	if !root.IsRoot {
+		return errors.New("box is not root")
+	}
+
+	s := svgWriter{w: bufio.NewWriter(out)}
 
-	s := fmt.Sprintf(`
-<svg 
-	xmlns="http://www.w3.org/2000/svg" 
-	xmlns:xlink="http://www.w3.org/1999/xlink" 
-	viewBox="0 0 %f %f" 
-	style="%s"
->`,
-		w,
-		h,
-		"background: white none repeat scroll 0% 0%;",
-	)
+	s.str(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 `)
+	s.float(w, coordPrecision)
+	s.str(" ")
+	s.float(h, coordPrecision)
+	s.str(`"`)
+	if !r.Theme.Transparent {
+		s.str(` style="background-color: `)
+		s.hex(r.Theme.Background())
+		s.str(`;"`)
+	}
+	// not img, since img hides nested lists of boxes from screen readers
+	s.str(` role="graphics-document">` + "\n")
+
+	// first title and description are name and description of document for screen readers
+	s.str("<title>")
+	xmlEscaper.WriteString(s.w, documentTitle(root))
+	s.str("</title>\n")
+	if root.Description != "" {
+		s.str("<desc>")
+		xmlEscaper.WriteString(s.w, root.Description)
+		s.str("</desc>\n")
+	}
+
+	s.str("<style><![CDATA[\n")
+	s.str(strings.ReplaceAll(r.Theme.style(), "]]>", "]]]]><![CDATA[>"))
+	s.str("]]></style>\n")
+
+	if root.Cushion {
+		s.str(cushionGradientSVG)
+	}
+
+	s.list(root.Children)
+
+	if len(root.Legend) > 0 {
+		s.str(`<g role="list" aria-label="legend">` + "\n")
+		for _, item := range root.Legend {
+			s.str(`<g role="listitem">`)
+			s.box(item.Swatch, "legend")
+			s.text(&item.Label)
+			s.str("</g>\n")
+		}
+		s.str("</g>\n")
+	}
 
-	var q UIBox
-	que := []UIBox{root}
-	for len(que) > 0 {
-		q, que = que[0], que[1:]
-		que = append(que, q.Children...)
-		s += BoxSVG(q) + "\n"
+	for i := range root.Annotations {
+		s.text(&root.Annotations[i])
 	}
 
-	s += `</svg>`
+	s.str("</svg>\n")
 
-	return []byte(s)
+	return s.w.Flush()
+}
+
+// documentTitle is title annotation, if present.
+func documentTitle(root UIBox) string {
+	for _, a := range root.Annotations {
+		if a.Class == "title" {
+			return a.Text
+		}
+	}
+	return "Treemap"
 }
 
 func BoxSVG(q UIBox) string {
-	if q.IsInvisible {
-		return ""
-	}
-	r, g, b, a := color.White.RGBA()
-	if q.Color != color.Opaque {
-		r, g, b, a = q.Color.RGBA()
-	}
-
-	r = r >> 8
-	g = g >> 8
-	b = b >> 8
-	o := float64(a>>8) / 255.0
-
-	br, bg, bb, ba := color.White.RGBA()
-	if q.BorderColor != color.Opaque {
-		br, bg, bb, ba = q.BorderColor.RGBA()
-	}
-	br = br >> 8
-	bg = bg >> 8
-	bb = bb >> 8
-	bo := float64(ba>>8) / 255.0
-
-	return fmt.Sprintf(`
-<g>
-	<rect x="%f" y="%f" width="%f" height="%f" style="%s" />
-	%s
-</g>
-`,
-		q.X,
-		q.Y,
-		q.W,
-		q.H,
-		fmt.Sprintf("fill: rgb(%d, %d, %d);opacity:1;fill-opacity:%.2f;stroke:rgb(%d,%d,%d);stroke-width:1px;stroke-opacity:%.2f;", r, g, b, o, br, bg, bb, bo),
-		TextSVG(q.Title),
-	)
+	var b strings.Builder
+	s := svgWriter{w: bufio.NewWriter(&b)}
+	s.box(q, boxClass(q))
+	s.w.Flush()
+	return b.String()
 }
 
 func TextSVG(t *UIText) string {
+	var b strings.Builder
+	s := svgWriter{w: bufio.NewWriter(&b)}
+	s.text(t)
+	s.w.Flush()
+	return b.String()
+}
+
+// svgWriter writes elements of SVG without intermediate strings.
+// Errors of writer are sticky and are returned on flush.
+type svgWriter struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+func (s *svgWriter) str(v string) { s.w.WriteString(v) }
+
+func (s *svgWriter) float(v float64, precision int) {
+	s.buf = appendCompactFloat(s.buf[:0], v, precision)
+	s.w.Write(s.buf)
+}
+
+func (s *svgWriter) uint(v uint32) {
+	s.buf = strconv.AppendUint(s.buf[:0], uint64(v), 10)
+	s.w.Write(s.buf)
+}
+
+// paint writes presentation attributes of color, opacity is written only if color is not opaque.
+func (s *svgWriter) paint(name string, c color.Color) {
+	s.str(" " + name + `="`)
+	a := s.hex(c)
+	s.str(`"`)
+
+	if a < 0xffff {
+		s.str(" " + name + `-opacity="`)
+		s.float(float64(a>>8)/255.0, coordPrecision)
+		s.str(`"`)
+	}
+}
+
+// hex writes color without opacity and returns opacity
+func (s *svgWriter) hex(c color.Color) uint32 {
+	r, g, b, a := c.RGBA()
+	s.buf = append(s.buf[:0], '#')
+	for _, v := range []uint32{r >> 8, g >> 8, b >> 8} {
+		if v > 0xff {
+			// colorful.Color out of gamut, such as from Hcl, does not clamp and returns channels over 0xffff
+			v = 0xff
+		}
+		s.buf = append(s.buf, hexDigits[v>>4], hexDigits[v&0xf])
+	}
+	s.w.Write(s.buf)
+	return a
+}
+
+const hexDigits = "0123456789abcdef"
+
+func (s *svgWriter) rect(q UIBox) {
+	s.str(`<rect x="`)
+	s.float(q.X, coordPrecision)
+	s.str(`" y="`)
+	s.float(q.Y, coordPrecision)
+	s.str(`" width="`)
+	s.float(q.W, coordPrecision)
+	s.str(`" height="`)
+	s.float(q.H, coordPrecision)
+	s.str(`"`)
+}
+
+// list nests groups of boxes same as tree, so that screen readers can navigate it.
+// Boxes have links, so box is next to list of its children and not around it.
+func (s *svgWriter) list(boxes []UIBox) {
+	if len(boxes) == 0 {
+		return
+	}
+	s.str(`<g role="list">` + "\n")
+	for _, q := range boxes {
+		s.str(`<g role="listitem"`)
+		if q.Description != "" {
+			s.str(` aria-label="`)
+			xmlEscaper.WriteString(s.w, q.Description)
+			s.str(`"`)
+		}
+		s.str(">")
+		s.box(q, boxClass(q))
+		s.list(q.Children)
+		s.str("</g>\n")
+	}
+	s.str("</g>\n")
+}
+
+func (s *svgWriter) box(q UIBox, class string) {
+	if q.IsInvisible {
+		return
+	}
+
+	link := q.URL != "" && isSafeURL(q.URL)
+	if link {
+		s.str(`<a xlink:href="`)
+		xmlEscaper.WriteString(s.w, q.URL)
+		s.str(`">`)
+	}
+
+	s.str(`<g class="` + class + `">`)
+	s.rect(q)
+	s.paint("fill", colorOr(q.Color, color.White))
+	s.paint("stroke", colorOr(q.BorderColor, color.White))
+	if q.BorderWidth > 0 {
+		s.str(` stroke-width="`)
+		s.float(q.BorderWidth, coordPrecision)
+		s.str(`"`)
+	}
+	s.str("/>")
+
+	if q.Description != "" {
+		// tooltip
+		s.str("<title>")
+		xmlEscaper.WriteString(s.w, q.Description)
+		s.str("</title>")
+	}
+
+	if q.Cushion {
+		s.rect(q)
+		s.str(` fill="url(#cushion)" stroke="none"/>`)
+	}
+
+	s.text(q.Title)
+	for i := range q.Lines {
+		s.text(&q.Lines[i])
+	}
+
+	s.str("</g>")
+	if link {
+		s.str("</a>")
+	}
+}
+
+// text has font in style of document
+func (s *svgWriter) text(t *UIText) {
 	if t == nil {
-		return ""
-	}
-
-	r, g, b, a := color.Black.RGBA()
-	if t.Color != color.Opaque {
-		r, g, b, a = t.Color.RGBA()
-	}
-
-	r = r >> 8
-	g = g >> 8
-	b = b >> 8
-	o := float64(a>>8) / 255.0
-
-	s := fmt.Sprintf(`
-<text 
-	data-notex="1" 
-	text-anchor="start"
-	transform="translate(%f,%f) scale(%f)"
-	style="%s" 
-	data-math="N">%s</text>
-		`,
-		t.X,
-		t.Y+t.H,
-		t.Scale,
-		fmt.Sprintf("font-family: Open Sans, verdana, arial, sans-serif !important; font-size: %dpx; fill: rgb(%d, %d, %d); fill-opacity: %.2f; white-space: pre;", fontSize, r, g, b, o),
-		xmlEscaper.Replace(t.Text),
-	)
-	return s
+		return
+	}
+
+	s.str(`<text class="label`)
+	if t.Class != "" {
+		s.str(" " + t.Class)
+	}
+	s.str(`" text-anchor="start" transform="translate(`)
+	s.float(t.X, coordPrecision)
+	s.str(",")
+	s.float(t.Y+t.H, coordPrecision)
+	s.str(") scale(")
+	s.float(t.Scale, scalePrecision)
+	s.str(`)"`)
+	s.paint("fill", colorOr(t.Color, color.Black))
+	s.outline(t.Outline)
+	s.str(">")
+	xmlEscaper.WriteString(s.w, t.Text)
+	s.str("</text>")
+}
+
+// outline draws stroke of text under its fill, which makes halo around letters
+func (s *svgWriter) outline(c color.Color) {
+	if c == nil {
+		return
+	}
+	s.paint("stroke", c)
+	s.str(` stroke-width="3" stroke-linejoin="round" paint-order="stroke"`)
+}
+
+// colorOr is default color when color is not set
+func colorOr(c color.Color, def color.Color) color.Color {
+	if c == nil || c == color.Opaque {
+		return def
+	}
+	return c
+}
+
+// appendCompactFloat appends float with fixed precision without trailing zeros.
+func appendCompactFloat(b []byte, v float64, precision int) []byte {
+	start := len(b)
+	b = strconv.AppendFloat(b, v, 'f', precision, 64)
+	if precision > 0 {
+		for b[len(b)-1] == '0' {
+			b = b[:len(b)-1]
+		}
+		if b[len(b)-1] == '.' {
+			b = b[:len(b)-1]
+		}
+	}
+	if string(b[start:]) == "-0" {
+		b = append(b[:start], '0')
+	}
+	return b
 }
@@ -0,0 +1,194 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestAppendCompactFloat(t *testing.T) {
+	tests := []struct {
+		v   float64
+		exp string
+	}{
+		{v: 0, exp: "0"},
+		{v: 12, exp: "12"},
+		{v: 12.5, exp: "12.5"},
+		{v: 12.345, exp: "12.35"},
+		{v: 0.001, exp: "0"},
+		{v: -0.001, exp: "0"},
+		{v: -3.1, exp: "-3.1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.exp, func(t *testing.T) {
+			if got := string(appendCompactFloat(nil, tc.v, 2)); got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRenderTo(t *testing.T) {
+	spec := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(makeWideTree(2, 3), 100, 100, 1, 1, 8)
+
+	var b bytes.Buffer
+	if err := (SVGRenderer{}).RenderTo(&b, spec, 100, 100); err != nil {
+		t.Fatal(err)
+	}
+	if exp := (SVGRenderer{}).Render(spec, 100, 100); !bytes.Equal(exp, b.Bytes()) {
+		t.Errorf("render to writer is different from render")
+	}
+
+	if err := (SVGRenderer{}).RenderTo(io.Discard, spec.Children[0], 100, 100); err == nil {
+		t.Errorf("expected error for box that is not root")
+	}
+}
+
+func TestHexOutOfGamut(t *testing.T) {
+	c := colorful.Hcl(0, 1, 0.5)
+	if r, _, _, _ := c.RGBA(); r <= 0xffff {
+		t.Fatalf("exp color out of gamut, got red(%x)", r)
+	}
+
+	var b strings.Builder
+	s := svgWriter{w: bufio.NewWriter(&b)}
+	s.hex(c)
+	s.w.Flush()
+	if exp := "#ff"; !strings.HasPrefix(b.String(), exp) {
+		t.Errorf("exp(%s) != got(%s)", exp, b.String())
+	}
+}
+
+func BenchmarkSVGRenderer(b *testing.B) {
+	for _, n := range []int{10, 30, 100} {
+		tree := makeWideTree(2, n)
+		spec := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(tree, 4096, 4096, 1, 1, 8)
+		b.Run(fmt.Sprintf("nodes_%d", len(tree.Nodes)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				SVGRenderer{}.Render(spec, 4096, 4096)
+			}
+		})
+		b.Run(fmt.Sprintf("to_writer_nodes_%d", len(tree.Nodes)), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := (SVGRenderer{}).RenderTo(io.Discard, spec, 4096, 4096); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessibleSVG(t *testing.T) {
+	tree := makeWideTree(2, 12)
+	builder := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White, Title: "Wide & deep"}
+	spec := builder.NewUITreeMap(tree, 800, 600, 1, 1, 8)
+	s := string(SVGRenderer{}.Render(spec, 800, 600))
+
+	for _, exp := range []string{
+		`role="graphics-document"`,
+		"<title>Wide &amp; deep</title>",
+		"<desc>Treemap of 156 nodes, largest at top level: 11 15.4%, 10 14.1%, 9 12.8%, 8 11.5%, 7 10.3%, 6 9.0%, 5 7.7%, 4 6.4%, 3 5.1%, 2 3.8%, and 2 more</desc>",
+		`<g role="listitem" aria-label="root/11/11, size 12, 15.4% of total">`,
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("exp(%s) is missing", exp)
+		}
+	}
+	if strings.Contains(s, "data-") {
+		t.Errorf("unexpected data attributes")
+	}
+	if strings.Contains(s, `role="img"`) {
+		t.Errorf("img role hides lists from screen readers")
+	}
+
+	// lists are nested same as tree
+	d := xml.NewDecoder(strings.NewReader(s))
+	var isList []bool
+	depth, maxDepth := 0, 0
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e := token.(type) {
+		case xml.StartElement:
+			list := false
+			for _, a := range e.Attr {
+				list = list || (a.Name.Local == "role" && a.Value == "list")
+			}
+			isList = append(isList, list)
+			if list {
+				depth++
+			}
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case xml.EndElement:
+			if isList[len(isList)-1] {
+				depth--
+			}
+			isList = isList[:len(isList)-1]
+		}
+	}
+	if maxDepth != 3 {
+		t.Errorf("exp(3) != got(%d) nested lists", maxDepth)
+	}
+}
+
+func TestTooltipAttributes(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":         {Path: "a", Name: "a", Size: 2},
+			"a/main.go": {Path: "a/main.go", Name: "main.go", Size: 2, Attributes: map[string]string{"owner": "team-a", "lang": "go"}},
+		},
+		To:   map[string][]string{"a": {"a/main.go"}},
+		Root: "a",
+	}
+	builder := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}
+	s := string(SVGRenderer{}.Render(builder.NewUITreeMap(tree, 400, 300, 1, 1, 8), 400, 300))
+
+	exp := "<title>a/main.go, size 2, 100.0% of total, lang: go, owner: team-a</title>"
+	if !strings.Contains(s, exp) {
+		t.Errorf("exp(%s) is missing in %s", exp, s)
+	}
+}
+
+func TestRenderToAllocationsPerBox(t *testing.T) {
+	// streaming keeps allocations per box constant, so that large trees render in linear time
+	for _, n := range []int{10, 100} {
+		tree := makeWideTree(2, n)
+		spec := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(tree, 4096, 4096, 1, 1, 8)
+		allocs := testing.AllocsPerRun(3, func() {
+			if err := (SVGRenderer{}).RenderTo(io.Discard, spec, 4096, 4096); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if perBox := allocs / float64(len(tree.Nodes)); perBox > 12 {
+			t.Errorf("nodes(%d): exp(<= 12) != got(%.1f) allocations per box", len(tree.Nodes), perBox)
+		}
+	}
+}
+
+func BenchmarkSVGRendererLarge(b *testing.B) {
+	tree := makeWideTree(2, 316)
+	spec := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White}.NewUITreeMap(tree, 16384, 16384, 1, 1, 8)
+	b.Run(fmt.Sprintf("nodes_%d", len(tree.Nodes)), func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			SVGRenderer{}.Render(spec, 16384, 16384)
+		}
+	})
+}
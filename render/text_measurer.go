@@ -0,0 +1,89 @@
+package render
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextMeasurer computes width of text in single line when it is rendered with font size.
+type TextMeasurer interface {
+	TextWidth(text string, fontSize float64) float64
+}
+
+// RuneCountTextMeasurer estimates every rune to be same width.
+// This overestimates narrow letters and underestimates wide ones.
+type RuneCountTextMeasurer struct{}
+
+func (s RuneCountTextMeasurer) TextWidth(text string, fontSize float64) float64 {
+	return textWidth(text, fontSize)
+}
+
+// HelveticaTextMeasurer uses advance widths of Helvetica, Arial has same widths.
+// Widths are for ASCII, other runes are estimated: wide East Asian and emoji take full em,
+// combining marks take no space and rest are average width of letter.
+type HelveticaTextMeasurer struct{}
+
+// helveticaWidths are advance widths from Helvetica AFM in 1/1000 em for runes from space to tilde.
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0 to 9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A to M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N to Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a to m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n to z
+	334, 260, 334, 584, // { to ~
+}
+
+const (
+	helveticaAverageWidth = 556
+	fullWidth             = 1000
+)
+
+func (s HelveticaTextMeasurer) TextWidth(text string, fontSize float64) float64 {
+	var w int
+	for _, r := range text {
+		w += helveticaRuneWidth(r)
+	}
+	return float64(w) * fontSize / 1000
+}
+
+func helveticaRuneWidth(r rune) int {
+	switch {
+	case r >= ' ' && r <= '~':
+		return helveticaWidths[r-' ']
+	case r == '…':
+		return 1000
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r) || unicode.IsControl(r):
+		return 0
+	case isWideRune(r):
+		return fullWidth
+	default:
+		return helveticaAverageWidth
+	}
+}
+
+// isWideRune is true for runes that are rendered in full em, such as CJK and emoji.
+func isWideRune(r rune) bool {
+	switch {
+	case r < 0x1100:
+		return false
+	case r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F, // CJK, Kana, Yi
+		r >= 0xAC00 && r <= 0xD7A3,                // Hangul Syllables
+		r >= 0xF900 && r <= 0xFAFF,                // CJK Compatibility Ideographs
+		r >= 0xFE30 && r <= 0xFE4F,                // CJK Compatibility Forms
+		r >= 0xFF00 && r <= 0xFF60,                // Fullwidth Forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, // emoji
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return true
+	}
+	return false
+}
+
+func textWidth(text string, fontSize float64) float64 {
+	return fontSize * float64(utf8.RuneCountInString(text)) * textWidthMultiplier
+}
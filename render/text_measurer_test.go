@@ -0,0 +1,39 @@
+package render
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHelveticaTextMeasurer(t *testing.T) {
+	tests := []struct {
+		text     string
+		expWidth float64
+	}{
+		{text: "iiii", expWidth: 4 * 0.222},
+		{text: "WWWW", expWidth: 4 * 0.944},
+		{text: "Go 1.17", expWidth: 0.778 + 0.556 + 0.278 + 0.556 + 0.278 + 0.556 + 0.556},
+		{text: "東京都", expWidth: 3},
+		{text: "한국", expWidth: 2},
+		{text: "🍬", expWidth: 1},
+		{text: "é", expWidth: 0.556},
+		{text: "ß", expWidth: 0.556},
+		{text: "e\u0301", expWidth: 0.556},
+	}
+	for _, tc := range tests {
+		t.Run(tc.text, func(t *testing.T) {
+			if w := (HelveticaTextMeasurer{}).TextWidth(tc.text, 1); math.Abs(tc.expWidth-w) > 0.0001 {
+				t.Errorf("wrong text width: exp(%f) != got(%f)", tc.expWidth, w)
+			}
+		})
+	}
+}
+
+func TestDefaultTextMeasurerHasWidthsOfDefaultFontFamily(t *testing.T) {
+	if f := (Theme{}).fontFamily(); f != HelveticaFontFamily {
+		t.Errorf("font family: exp(%s) != got(%s)", HelveticaFontFamily, f)
+	}
+	if m := (UITreeMapBuilder{}).textMeasurer(); m != (HelveticaTextMeasurer{}) {
+		t.Errorf("text measurer: exp(HelveticaTextMeasurer) != got(%T)", m)
+	}
+}
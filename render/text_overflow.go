@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+)
+
+const ellipsis = "…"
+
+// TextOverflow defines what to do with line of label that is wider than box.
+type TextOverflow int
+
+const (
+	ScaleTextOverflow    TextOverflow = iota // scale down font of line
+	WrapTextOverflow                         // wrap on spaces and path segments, words that do not fit are scaled down
+	EllipsisTextOverflow                     // cut out middle of line, path segments are cut out whole if possible
+)
+
+var textOverflowNames = map[TextOverflow]string{
+	ScaleTextOverflow:    "scale",
+	WrapTextOverflow:     "wrap",
+	EllipsisTextOverflow: "ellipsis",
+}
+
+func (o TextOverflow) String() string { return textOverflowNames[o] }
+
+// ParseTextOverflow returns text overflow by its name.
+func ParseTextOverflow(name string) (TextOverflow, error) {
+	for o, n := range textOverflowNames {
+		if n == name {
+			return o, nil
+		}
+	}
+	return ScaleTextOverflow, fmt.Errorf("unknown text overflow(%s)", name)
+}
+
+// Lines splits or cuts text into lines that fit width W at font size when possible.
+func (o TextOverflow) Lines(m TextMeasurer, text string, fontSize int, W float64) []string {
+	if m.TextWidth(text, float64(fontSize)) <= W {
+		return []string{text}
+	}
+	switch o {
+	case WrapTextOverflow:
+		return wrapText(m, text, fontSize, W)
+	case EllipsisTextOverflow:
+		if s := middleEllipsis(m, text, fontSize, W); s != "" {
+			return []string{s}
+		}
+		return nil
+	default:
+		return []string{text}
+	}
+}
+
+// wrapText greedily fills lines with words, words end with space or slash.
+func wrapText(m TextMeasurer, text string, fontSize int, W float64) []string {
+	var lines []string
+	var line string
+	for _, word := range splitWords(text) {
+		if line != "" && m.TextWidth(strings.TrimSpace(line+word), float64(fontSize)) > W {
+			lines = append(lines, strings.TrimSpace(line))
+			line = ""
+		}
+		line += word
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitWords keeps delimiters at end of words, so that joined words are same as text.
+func splitWords(text string) []string {
+	var words []string
+	for len(text) > 0 {
+		i := strings.IndexAny(text, " /")
+		if i < 0 {
+			words = append(words, text)
+			break
+		}
+		words = append(words, text[:i+1])
+		text = text[i+1:]
+	}
+	return words
+}
+
+// middleEllipsis keeps start and end of text, which for paths are most informative.
+// For paths whole middle segments are cut out first, e.g. github.com/…/render.
+// Returns empty string if not even one rune from start and end fits.
+func middleEllipsis(m TextMeasurer, text string, fontSize int, W float64) string {
+	fits := func(s string) bool { return m.TextWidth(s, float64(fontSize)) <= W }
+
+	if segments := strings.Split(text, "/"); len(segments) > 2 {
+		// cut out more and more segments around middle
+		for n := 1; n <= len(segments)-2; n++ {
+			i := (len(segments) - n) / 2
+			if i == 0 {
+				i = 1
+			}
+			s := strings.Join(segments[:i], "/") + "/" + ellipsis + "/" + strings.Join(segments[i+n:], "/")
+			if fits(s) {
+				return s
+			}
+		}
+	}
+
+	runes := []rune(text)
+	for n := len(runes) - 1; n > 1; n-- {
+		head := (n + 1) / 2
+		s := string(runes[:head]) + ellipsis + string(runes[len(runes)-(n-head):])
+		if fits(s) {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestTextOverflowLines(t *testing.T) {
+	// with font size 10 each rune is 8 wide
+	tests := []struct {
+		name     string
+		overflow TextOverflow
+		text     string
+		w        float64
+		exp      []string
+	}{
+		{
+			name:     "when text fits, then same text",
+			overflow: WrapTextOverflow,
+			text:     "github.com/nikolaydubina/treemap",
+			w:        1000,
+			exp:      []string{"github.com/nikolaydubina/treemap"},
+		},
+		{
+			name:     "when scale, then same text",
+			overflow: ScaleTextOverflow,
+			text:     "github.com/nikolaydubina/treemap",
+			w:        80,
+			exp:      []string{"github.com/nikolaydubina/treemap"},
+		},
+		{
+			name:     "when wrap, then words on lines",
+			overflow: WrapTextOverflow,
+			text:     "Congo, Dem. Rep.",
+			w:        80,
+			exp:      []string{"Congo,", "Dem. Rep."},
+		},
+		{
+			name:     "when wrap path, then segments on lines and long segment on own line",
+			overflow: WrapTextOverflow,
+			text:     "github.com/nikolaydubina/treemap/render",
+			w:        100,
+			exp:      []string{"github.com/", "nikolaydubina/", "treemap/", "render"},
+		},
+		{
+			name:     "when ellipsis path, then middle segments are cut out",
+			overflow: EllipsisTextOverflow,
+			text:     "github.com/nikolaydubina/treemap/render",
+			w:        160,
+			exp:      []string{"github.com/…/render"},
+		},
+		{
+			name:     "when ellipsis, then middle of text is cut out",
+			overflow: EllipsisTextOverflow,
+			text:     "abcdefghij",
+			w:        40,
+			exp:      []string{"ab…ij"},
+		},
+		{
+			name:     "when ellipsis and nothing fits, then no lines",
+			overflow: EllipsisTextOverflow,
+			text:     "abcdefghij",
+			w:        16,
+			exp:      nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.overflow.Lines(RuneCountTextMeasurer{}, tc.text, 10, tc.w); !reflect.DeepEqual(tc.exp, got) {
+				t.Errorf("exp(%#v) != got(%#v)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestMinFontSize(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a": {Path: "a", Name: "a-very-long-name-of-node-that-does-not-fit", Size: 1},
+		},
+		Root: "a",
+	}
+
+	tests := []struct {
+		name     string
+		min      float64
+		expTitle bool
+	}{
+		{name: "when no minimum, then title is scaled down", min: 0, expTitle: true},
+		{name: "when scaled down below minimum, then no title", min: 8, expTitle: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := UITreeMapBuilder{Colorer: NoneColorer{}, MinFontSize: tc.min}
+			box := builder.NewUIBox("a", tree, 0, 0, 100, 100, 0, 0)
+			if got := box.Title != nil; got != tc.expTitle {
+				t.Errorf("exp(%v) != got(%v)", tc.expTitle, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package render
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultFontFamily is font of text, it has widths of HelveticaTextMeasurer that is default of builder.
+	DefaultFontFamily = HelveticaFontFamily
+	// HelveticaFontFamily has same glyph widths as HelveticaTextMeasurer.
+	HelveticaFontFamily = "Helvetica, Arial, sans-serif"
+	// OpenSansFontFamily has no embedded glyph widths, widths of labels are estimated by RuneCountTextMeasurer.
+	OpenSansFontFamily = "Open Sans, verdana, arial, sans-serif"
+)
+
+var (
+	LightBackgroundColor color.Color = color.White
+	DarkBackgroundColor  color.Color = color.RGBA{13, 17, 23, 255}
+)
+
+// Theme is style of SVG document.
+// Boxes are groups with classes that can be styled by CSS:
+// box, depth-<depth from tree root>, leaf or internal, category-<category>, highlight.
+// Legend swatches have class legend, text has class label, annotations also have class title, subtitle or footer.
+// Colors of boxes are presentation attributes, so any CSS rule overrides them.
+type Theme struct {
+	FontFamily  string // default is DefaultFontFamily, labels fit boxes if TextMeasurer of builder has widths of this font
+	CSS         string // added after default style, so it overrides default style
+	Dark        bool   // dark background
+	Transparent bool   // no background, page under document is expected to be dark for dark theme
+}
+
+// Background is color under boxes, it is same for transparent document since page is expected to be of this color.
+func (t Theme) Background() color.Color {
+	if t.Dark {
+		return DarkBackgroundColor
+	}
+	return LightBackgroundColor
+}
+
+func (t Theme) fontFamily() string {
+	if t.FontFamily == "" {
+		return DefaultFontFamily
+	}
+	return t.FontFamily
+}
+
+// style is content of style element of document.
+func (t Theme) style() string {
+	s := ".label { font-family: " + t.fontFamily() + "; font-size: " + strconv.Itoa(fontSize) + "px; white-space: pre; }\n"
+	if t.CSS != "" {
+		s += t.CSS + "\n"
+	}
+	return s
+}
+
+// boxClass lists classes of box for CSS.
+func boxClass(q UIBox) string {
+	s := "box depth-" + strconv.Itoa(q.Depth)
+	if q.IsLeaf {
+		s += " leaf"
+	} else {
+		s += " internal"
+	}
+	if q.Category != "" {
+		s += " category-" + cssClassName(q.Category)
+	}
+	if q.Highlighted {
+		s += " highlight"
+	}
+	return s
+}
+
+// cssClassName makes lowercase class name from text by replacing runes that are not letters or digits with dashes.
+func cssClassName(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, text)
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/nikolaydubina/treemap"
+)
+
+func TestBoxClass(t *testing.T) {
+	tests := []struct {
+		name string
+		box  UIBox
+		exp  string
+	}{
+		{name: "when internal, then internal", box: UIBox{Depth: 0}, exp: "box depth-0 internal"},
+		{name: "when leaf, then leaf", box: UIBox{Depth: 2, IsLeaf: true}, exp: "box depth-2 leaf"},
+		{name: "when category, then category class", box: UIBox{Depth: 1, IsLeaf: true, Category: "North America"}, exp: "box depth-1 leaf category-north-america"},
+		{name: "when category has symbols, then dashes", box: UIBox{Depth: 1, Category: "a/b.c_d"}, exp: "box depth-1 internal category-a-b-c_d"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := boxClass(tc.box); got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestThemeStyle(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{
+			"a":   {Path: "a", Name: "a", Size: 1},
+			"a/b": {Path: "a/b", Name: "b", Size: 1},
+		},
+		To:   map[string][]string{"a": {"a/b"}},
+		Root: "a",
+	}
+	palette, _ := GetCategoricalPalette("Tableau10")
+	colorer := NewCategoricalColorer(tree, palette, "")
+	spec := UITreeMapBuilder{Colorer: colorer, BorderColor: color.White}.NewUITreeMap(tree, 100, 100, 1, 1, 8)
+
+	renderer := SVGRenderer{Theme: Theme{FontFamily: "Brand Sans", CSS: ".leaf rect { stroke-width: 3px; }"}}
+	s := string(renderer.Render(spec, 100, 100))
+
+	for _, exp := range []string{
+		"font-family: Brand Sans;",
+		".leaf rect { stroke-width: 3px; }",
+		`<g class="box depth-0 internal category-b">`,
+		`<g class="box depth-1 leaf category-b">`,
+		`<text class="label"`,
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("exp(%s) is missing", exp)
+		}
+	}
+	if strings.Contains(s, "style=\"fill") {
+		t.Errorf("boxes should not have inline style")
+	}
+}
+
+func TestThemeBackground(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{"a": {Path: "a", Name: "a", Size: 1}},
+		Root:  "a",
+	}
+
+	tests := []struct {
+		name          string
+		theme         Theme
+		expText       color.Color
+		expBackground string
+	}{
+		{name: "when light, then dark text on white", theme: Theme{}, expText: DarkTextColor, expBackground: `style="background-color: #ffffff;"`},
+		{name: "when dark, then light text on dark", theme: Theme{Dark: true}, expText: LightTextColor, expBackground: `style="background-color: #0d1117;"`},
+		{name: "when dark and transparent, then light text and no background", theme: Theme{Dark: true, Transparent: true}, expText: LightTextColor, expBackground: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := UITreeMapBuilder{Colorer: NoneColorer{}, BorderColor: color.White, Background: tc.theme.Background()}
+			spec := builder.NewUITreeMap(tree, 100, 100, 1, 1, 8)
+			if got := spec.Children[0].Title.Color; got != tc.expText {
+				t.Errorf("exp(%v) != got(%v)", tc.expText, got)
+			}
+
+			s := string(SVGRenderer{Theme: tc.theme}.Render(spec, 100, 100))
+			if got := strings.Contains(s, "background-color"); got != (tc.expBackground != "") {
+				t.Errorf("background: exp(%v) != got(%v)", tc.expBackground != "", got)
+			}
+			if tc.expBackground != "" && !strings.Contains(s, tc.expBackground) {
+				t.Errorf("exp(%s) is missing", tc.expBackground)
+			}
+		})
+	}
+}
+
+func TestTextColorOnBackground(t *testing.T) {
+	tree := treemap.Tree{
+		Nodes: map[string]treemap.Node{"a": {Path: "a", Name: "a", Size: 1}},
+		Root:  "a",
+	}
+	textColor := color.RGBA{0, 0, 255, 255}
+
+	tests := []struct {
+		name    string
+		box     color.Color
+		exp     color.Color
+		dark    bool
+		shading float64
+	}{
+		{name: "when box is opaque, then text color of colorer", box: color.RGBA{255, 255, 0, 255}, dark: true, exp: textColor},
+		{name: "when box is transparent, then contrast with background", box: color.Transparent, dark: true, exp: LightTextColor},
+		{name: "when box is shaded, then contrast with shaded box", box: color.White, shading: 0.1, exp: DarkTextColor},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := UITreeMapBuilder{
+				Colorer:     testColorer{box: tc.box, text: textColor},
+				BorderColor: color.White,
+				Background:  Theme{Dark: tc.dark}.Background(),
+				DepthStyle:  DepthStyle{Shade: tc.shading},
+			}
+			spec := builder.NewUITreeMap(tree, 100, 100, 1, 1, 8)
+			if got := spec.Children[0].Title.Color; got != tc.exp {
+				t.Errorf("exp(%v) != got(%v)", tc.exp, got)
+			}
+		})
+	}
+}
@@ -10,11 +10,12 @@ import (
 
 // TreeHueColorer this algorithm will split Hue in NCL ranges such that deeper nodes have more specific hue.
 // The advantage of this coloring is that nodes in that belong topologically close will have similar hue.
-// Supposed to be run once on tree due to memoization.
-// The challenge that not all HCL values are valid colors. Which is why we have to sample and look for value within range.
-// For very deep trees, that require precise colors colors closer to leaves will get mixed due to sampling.
+// Hues can be computed once by TreeHues, colorer never writes to them, so it can be shared.
+// The challenge that not all HCL values are valid colors. Which is why we have to look for valid value within range.
+// Search is deterministic, it picks valid color closest to target lightness with highest chroma up to target.
+// For very deep trees, that require precise colors colors closer to leaves will get mixed due to tolerance.
 type TreeHueColorer struct {
-	Hues   map[string]float64 // memoized hues
+	Hues   map[string]float64 // precomputed hues, if empty then hues are computed on each call
 	C      float64            // will be in all colors
 	L      float64            // will be in all colors
 	Offset float64            // 0 ~ 360 hue offset in HCL for tree
@@ -23,40 +24,45 @@ type TreeHueColorer struct {
 	DeltaL float64            // tolerance for approximate color
 }
 
+// lightnessSearchSteps is number of steps on each side of target lightness within tolerance
+const lightnessSearchSteps = 8
+
 func (s TreeHueColorer) ColorBox(tree treemap.Tree, node string) color.Color {
-	if len(s.Hues) == 0 {
-		for k, v := range TreeHues(tree, s.Offset) {
-			s.Hues[k] = v
-		}
+	hues := s.Hues
+	if len(hues) == 0 {
+		hues = TreeHues(tree, s.Offset)
 	}
 
-	// some of HCL is not valid. using generator from go-colorful package to get one colour
-	f := func(l, a, b float64) bool {
-		// target
-		th, tc, tl := s.Hues[node], s.C, s.L
-		// current
-		h, c, l := colorful.LabToHcl(l, a, b)
-		// withing range
-		return (math.Abs(h-th) < s.DeltaH) && (math.Abs(c-tc) < s.DeltaC) && (math.Abs(l-tl) < s.DeltaL)
-	}
-	palette, err := colorful.SoftPaletteEx(1, colorful.SoftPaletteSettings{CheckColor: f, Iterations: 500, ManySamples: true})
-	if err != nil {
-		// white
-		return colorful.Hcl(0, 0, 1)
+	// some of HCL is not valid. search from target lightness outwards, first valid color within range wins
+	h := hues[node]
+	for i := 0; i <= lightnessSearchSteps; i++ {
+		dl := s.DeltaL * float64(i) / float64(lightnessSearchSteps+1)
+		for _, l := range [2]float64{s.L - dl, s.L + dl} {
+			if l < 0 || l > 1 {
+				continue
+			}
+			if c := closestValidHcl(h, s.C, l); withinTolerance(c, h, s.C, l, s.DeltaH, s.DeltaC) {
+				return c
+			}
+		}
 	}
 
-	return palette[0]
+	// white
+	return colorful.Hcl(0, 0, 1)
 }
 
-func (s TreeHueColorer) ColorText(tree treemap.Tree, node string) color.Color {
-	boxColor := s.ColorBox(tree, node).(colorful.Color)
-	_, _, l := boxColor.Hcl()
-	switch {
-	case l > 0.5:
-		return DarkTextColor
-	default:
-		return LightTextColor
+func withinTolerance(col colorful.Color, th, tc, tl, dh, dc float64) bool {
+	h, c, _ := col.Hcl()
+	dhue := math.Abs(h - th)
+	if dhue > 180 {
+		dhue = 360 - dhue
 	}
+	// hue of grey is undefined
+	return (c < 0.0001 || dhue < dh) && (math.Abs(c-tc) < dc)
+}
+
+func (s TreeHueColorer) ColorText(tree treemap.Tree, node string) color.Color {
+	return ContrastTextColor(s.ColorBox(tree, node))
 }
 
 func TreeHues(tree treemap.Tree, offset float64) map[string]float64 {
@@ -65,3 +65,15 @@ func TestTreeHues(t *testing.T) {
 		})
 	}
 }
+
+func TestTreeHueColorerDoesNotWriteHues(t *testing.T) {
+	tree := makeWideTree(2, 4)
+	hues := map[string]float64{}
+	colorer := TreeHueColorer{Hues: hues, C: 0.5, L: 0.5, DeltaH: 10, DeltaC: 0.3, DeltaL: 0.1}
+	for node := range tree.Nodes {
+		colorer.ColorBox(tree, node)
+	}
+	if len(hues) != 0 {
+		t.Errorf("exp(0) != got(%d) hues", len(hues))
+	}
+}
@@ -24,15 +24,16 @@ func (s SumSizeImputer) ImputeSizeNode(t Tree, node string) {
 			v = sum
 		}
 
-		var name string
-		if parts := strings.Split(node, "/"); len(parts) > 0 {
-			name = parts[len(parts)-1]
+		if !ok {
+			n.Path = node
 		}
-
-		t.Nodes[node] = Node{
-			Path: node,
-			Name: name,
-			Size: v,
+		if n.Name == "" {
+			if parts := strings.Split(node, "/"); len(parts) > 0 {
+				n.Name = parts[len(parts)-1]
+			}
 		}
+
+		n.Size = v
+		t.Nodes[node] = n
 	}
 }
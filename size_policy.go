@@ -0,0 +1,60 @@
+package treemap
+
+import (
+	"fmt"
+	"math"
+)
+
+// SizePolicy defines what to do with sizes that are negative, NaN or Inf.
+type SizePolicy int
+
+const (
+	KeepInvalidSize   SizePolicy = iota // keep as is, layout treats such sizes as zero
+	RejectInvalidSize                   // return error
+	ClampInvalidSize                    // set to zero
+	AbsInvalidSize                      // use absolute value of negative size, NaN and Inf are set to zero
+)
+
+var sizePolicyNames = map[SizePolicy]string{
+	KeepInvalidSize:   "keep",
+	RejectInvalidSize: "reject",
+	ClampInvalidSize:  "clamp",
+	AbsInvalidSize:    "abs",
+}
+
+func (p SizePolicy) String() string { return sizePolicyNames[p] }
+
+// ParseSizePolicy returns policy by its name.
+func ParseSizePolicy(name string) (SizePolicy, error) {
+	for p, n := range sizePolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return KeepInvalidSize, fmt.Errorf("unknown size policy(%s)", name)
+}
+
+// SanitizeSize returns size that is finite and not negative according to policy.
+func (p SizePolicy) SanitizeSize(v float64) (float64, error) {
+	isFinite := !math.IsNaN(v) && !math.IsInf(v, 0)
+	if isFinite && v >= 0 {
+		return v, nil
+	}
+
+	switch p {
+	case KeepInvalidSize:
+		return v, nil
+	case ClampInvalidSize:
+		return 0, nil
+	case AbsInvalidSize:
+		if isFinite {
+			return math.Abs(v), nil
+		}
+		return 0, nil
+	default:
+		if !isFinite {
+			return 0, fmt.Errorf("size(%v) is not finite", v)
+		}
+		return 0, fmt.Errorf("size(%v) is negative", v)
+	}
+}
@@ -1,21 +1,46 @@
 package treemap
 
-import "strings"
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // for numerical stability
 const minHeatDifferenceForHeatmap float64 = 0.0000001
 
+// FakeRoot is identifier of root that parser adds above multiple roots of input, it has no name and is not shown.
+const FakeRoot = "some-secret-string"
+
 type Node struct {
-	Path    string
-	Name    string
-	Size    float64
-	Heat    float64
-	HasHeat bool
+	Path       string
+	Name       string
+	Size       float64
+	Heat       float64
+	HasHeat    bool
+	RawHeat    float64           // heat in units of input, normalization changes only Heat
+	HasRawHeat bool              // raw heat is set, otherwise normalization keeps heat as raw heat
+	Attributes map[string]string // extra values of node, such as additional columns of input
+}
+
+// NumericAttribute returns attribute value as number, if it is present and is a number.
+func (n Node) NumericAttribute(name string) (float64, bool) {
+	v, ok := n.Attributes[name]
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
 }
 
 type Tree struct {
-	Nodes map[string]Node		// node identifier (path) -> Node
-	To    map[string][]string	// node identifier (path) -> list of node identifiers (paths) for edges from it (to children)
+	Nodes map[string]Node     // node identifier (path) -> Node
+	To    map[string][]string // node identifier (path) -> list of node identifiers (paths) for edges from it (to children)
 	Root  string
 }
 
@@ -49,7 +74,13 @@ func (t Tree) HeatRange() (minHeat float64, maxHeat float64) {
 	return minHeat, maxHeat
 }
 
+// NormalizeHeat maps heat linearly into [0, 1], heat before normalization is kept as raw heat.
 func (t Tree) NormalizeHeat() {
+	keepRawHeat(t)
+	t.normalizeHeat()
+}
+
+func (t Tree) normalizeHeat() {
 	minHeat, maxHeat := t.HeatRange()
 
 	if (maxHeat - minHeat) < minHeatDifferenceForHeatmap {
@@ -61,17 +92,151 @@ func (t Tree) NormalizeHeat() {
 			continue
 		}
 
-		n := Node{
-			Path:    node.Path,
-			Name:    node.Name,
-			Size:    node.Size,
-			Heat:    (node.Heat - minHeat) / (maxHeat - minHeat),
-			HasHeat: true,
+		node.Heat = (node.Heat - minHeat) / (maxHeat - minHeat)
+		t.Nodes[path] = node
+	}
+}
+
+// keepRawHeat sets raw heat of nodes that have heat but no raw heat, such as nodes of trees made in code.
+// Normalizers call it before changing heat, so that heat in units of input is not lost.
+// Raw heat that is set is not changed, so normalizing again keeps raw heat of input.
+func keepRawHeat(t Tree) {
+	for path, node := range t.Nodes {
+		if node.HasHeat && !node.HasRawHeat {
+			node.RawHeat = node.Heat
+			node.HasRawHeat = true
+			t.Nodes[path] = node
 		}
-		t.Nodes[path] = n
 	}
 }
 
+// ValidationError lists all problems found in tree.
+// Use errors.As to get it from error returned by Validate and inspect each of Problems.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e ValidationError) Error() string {
+	s := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		s[i] = p.Error()
+	}
+	return fmt.Sprintf("invalid tree: %s", strings.Join(s, "; "))
+}
+
+// Validate checks invariants of tree that rest of package assumes.
+// Returns ValidationError with every problem found, or nil if tree is valid.
+// Root can have no entry in Nodes, as long as it has children.
+func (t Tree) Validate() error {
+	var problems []error
+
+	if _, ok := t.Nodes[t.Root]; !ok && len(t.To[t.Root]) == 0 {
+		problems = append(problems, fmt.Errorf("root(%s) does not exist", t.Root))
+	}
+
+	parents := map[string][]string{}
+	for _, from := range sortedEdgeKeys(t.To) {
+		for _, to := range t.To[from] {
+			parents[to] = append(parents[to], from)
+			if _, ok := t.Nodes[to]; !ok {
+				problems = append(problems, fmt.Errorf("node(%s) is child of node(%s) but has no entry in nodes", to, from))
+			}
+		}
+	}
+
+	for _, node := range sortedEdgeKeys(parents) {
+		if len(parents[node]) > 1 {
+			problems = append(problems, fmt.Errorf("node(%s) has multiple parents(%s)", node, strings.Join(parents[node], ", ")))
+		}
+	}
+	if p, ok := parents[t.Root]; ok {
+		problems = append(problems, fmt.Errorf("root(%s) has parents(%s)", t.Root, strings.Join(p, ", ")))
+	}
+
+	for _, cycle := range t.cycles() {
+		problems = append(problems, fmt.Errorf("cycle(%s)", strings.Join(cycle, " -> ")))
+	}
+
+	for _, key := range sortedNodeKeys(t.Nodes) {
+		node := t.Nodes[key]
+		if node.Path != key {
+			problems = append(problems, fmt.Errorf("node(%s) has different path(%s)", key, node.Path))
+		}
+		switch {
+		case math.IsNaN(node.Size) || math.IsInf(node.Size, 0):
+			problems = append(problems, fmt.Errorf("node(%s) size(%v) is not finite", key, node.Size))
+		case node.Size < 0:
+			problems = append(problems, fmt.Errorf("node(%s) size(%v) is negative", key, node.Size))
+		}
+		if node.HasHeat && (math.IsNaN(node.Heat) || math.IsInf(node.Heat, 0)) {
+			problems = append(problems, fmt.Errorf("node(%s) heat(%v) is not finite", key, node.Heat))
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// cycles finds cycles in edges by depth first search, each cycle is reported once.
+func (t Tree) cycles() [][]string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+
+	var cycles [][]string
+	var stack []string
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		stack = append(stack, node)
+		for _, child := range t.To[node] {
+			switch state[child] {
+			case visiting:
+				for i := range stack {
+					if stack[i] == child {
+						cycle := append([]string{}, stack[i:]...)
+						cycles = append(cycles, append(cycle, child))
+					}
+				}
+			case 0:
+				visit(child)
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[node] = visited
+	}
+
+	visit(t.Root)
+	for _, node := range sortedEdgeKeys(t.To) {
+		if state[node] == 0 {
+			visit(node)
+		}
+	}
+	return cycles
+}
+
+func sortedNodeKeys(m map[string]Node) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedEdgeKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // SetNamesFromPaths will update each node to its path leaf as name.
 func SetNamesFromPaths(t *Tree) {
 	if t == nil {
@@ -83,12 +248,7 @@ func SetNamesFromPaths(t *Tree) {
 			continue
 		}
 
-		t.Nodes[path] = Node{
-			Path:    node.Path,
-			Name:    parts[len(parts)-1],
-			Size:    node.Size,
-			Heat:    node.Heat,
-			HasHeat: node.HasHeat,
-		}
+		node.Name = parts[len(parts)-1]
+		t.Nodes[path] = node
 	}
 }
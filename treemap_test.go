@@ -0,0 +1,210 @@
+package treemap
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		tree     Tree
+		expProbs []string
+	}{
+		{
+			name: "when valid tree, then no error",
+			tree: Tree{
+				Nodes: map[string]Node{
+					"a":   {Path: "a", Size: 2},
+					"a/b": {Path: "a/b", Size: 1, Heat: 1, HasHeat: true},
+				},
+				To:   map[string][]string{"a": {"a/b"}},
+				Root: "a",
+			},
+		},
+		{
+			name: "when root has only children, then no error",
+			tree: Tree{
+				Nodes: map[string]Node{
+					"a": {Path: "a"},
+					"b": {Path: "b"},
+				},
+				To:   map[string][]string{FakeRoot: {"a", "b"}},
+				Root: FakeRoot,
+			},
+		},
+		{
+			name: "when root is missing, then error",
+			tree: Tree{
+				Nodes: map[string]Node{"a": {Path: "a"}},
+				Root:  "b",
+			},
+			expProbs: []string{"root(b) does not exist"},
+		},
+		{
+			name: "when child has no node, then error",
+			tree: Tree{
+				Nodes: map[string]Node{"a": {Path: "a"}},
+				To:    map[string][]string{"a": {"a/b"}},
+				Root:  "a",
+			},
+			expProbs: []string{"node(a/b) is child of node(a) but has no entry in nodes"},
+		},
+		{
+			name: "when node has two parents, then error",
+			tree: Tree{
+				Nodes: map[string]Node{
+					"a":   {Path: "a"},
+					"a/b": {Path: "a/b"},
+					"a/c": {Path: "a/c"},
+				},
+				To: map[string][]string{
+					"a":   {"a/b", "a/c"},
+					"a/b": {"a/c"},
+				},
+				Root: "a",
+			},
+			expProbs: []string{"node(a/c) has multiple parents(a, a/b)"},
+		},
+		{
+			name: "when cycle, then error",
+			tree: Tree{
+				Nodes: map[string]Node{
+					"a":   {Path: "a"},
+					"a/b": {Path: "a/b"},
+				},
+				To: map[string][]string{
+					"a":   {"a/b"},
+					"a/b": {"a"},
+				},
+				Root: "a",
+			},
+			expProbs: []string{
+				"root(a) has parents(a/b)",
+				"cycle(a -> a/b -> a)",
+			},
+		},
+		{
+			name: "when bad sizes and heat, then error for each",
+			tree: Tree{
+				Nodes: map[string]Node{
+					"a":   {Path: "a", Size: math.Inf(1)},
+					"a/b": {Path: "a/b", Size: -1},
+					"a/c": {Path: "a/c", Size: math.NaN()},
+					"a/d": {Path: "a/d", Heat: math.NaN(), HasHeat: true},
+				},
+				To:   map[string][]string{"a": {"a/b", "a/c", "a/d"}},
+				Root: "a",
+			},
+			expProbs: []string{
+				"node(a) size(+Inf) is not finite",
+				"node(a/b) size(-1) is negative",
+				"node(a/c) size(NaN) is not finite",
+				"node(a/d) heat(NaN) is not finite",
+			},
+		},
+		{
+			name: "when path does not match key, then error",
+			tree: Tree{
+				Nodes: map[string]Node{"a": {Path: "b"}},
+				Root:  "a",
+			},
+			expProbs: []string{"node(a) has different path(b)"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tree.Validate()
+
+			if len(tc.expProbs) == 0 {
+				if err != nil {
+					t.Error(err)
+				}
+				return
+			}
+
+			var verr ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got(%v)", err)
+			}
+			if len(verr.Problems) != len(tc.expProbs) {
+				t.Errorf("problems: exp(%d) != got(%d): %s", len(tc.expProbs), len(verr.Problems), err)
+			}
+			for _, p := range tc.expProbs {
+				if !strings.Contains(err.Error(), p) {
+					t.Errorf("expected problem(%s) in error(%s)", p, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCollapseLongPathsKeepsTreeValid(t *testing.T) {
+	tree := Tree{
+		Nodes: map[string]Node{
+			"a":       {Path: "a", Name: "a"},
+			"a/b":     {Path: "a/b", Name: "b"},
+			"a/b/c":   {Path: "a/b/c", Name: "c", Size: 1},
+			"a/b/d":   {Path: "a/b/d", Name: "d", Size: 1},
+			"a/b/d/e": {Path: "a/b/d/e", Name: "e", Size: 1},
+		},
+		To: map[string][]string{
+			"a":     {"a/b"},
+			"a/b":   {"a/b/c", "a/b/d"},
+			"a/b/d": {"a/b/d/e"},
+		},
+		Root: "a",
+	}
+
+	CollapseLongPaths(&tree)
+
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+	if tree.Root != "a/b" || tree.Nodes["a/b"].Name != "a/b" {
+		t.Errorf("root: got(%s) name(%s)", tree.Root, tree.Nodes[tree.Root].Name)
+	}
+	if children := tree.To["a/b"]; len(children) != 2 || children[1] != "a/b/d/e" {
+		t.Errorf("children: got(%v)", children)
+	}
+	if name := tree.Nodes["a/b/d/e"].Name; name != "d/e" {
+		t.Errorf("collapsed name: got(%s)", name)
+	}
+}
+
+func TestCollapseLongPathsFromNodeUpdatesEdges(t *testing.T) {
+	tree := Tree{
+		Nodes: map[string]Node{
+			"a":       {Path: "a", Name: "a"},
+			"a/b":     {Path: "a/b", Name: "b"},
+			"a/b/c":   {Path: "a/b/c", Name: "c", Size: 1},
+			"a/d":     {Path: "a/d", Name: "d"},
+			"a/d/e":   {Path: "a/d/e", Name: "e"},
+			"a/d/e/f": {Path: "a/d/e/f", Name: "f", Size: 1},
+		},
+		To: map[string][]string{
+			"a":     {"a/b", "a/d"},
+			"a/b":   {"a/b/c"},
+			"a/d":   {"a/d/e"},
+			"a/d/e": {"a/d/e/f"},
+		},
+		Root: "a",
+	}
+
+	CollapseLongPathsFromNode(&tree, "a/d")
+
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+	if children := tree.To["a"]; len(children) != 2 || children[0] != "a/b" || children[1] != "a/d/e/f" {
+		t.Errorf("children: got(%v)", children)
+	}
+	if name := tree.Nodes["a/d/e/f"].Name; name != "d/e/f" {
+		t.Errorf("collapsed name: got(%s)", name)
+	}
+	if _, ok := tree.Nodes["a/b/c"]; !ok {
+		t.Errorf("other branch is not expected to be collapsed")
+	}
+}
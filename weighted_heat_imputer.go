@@ -1,6 +1,7 @@
 package treemap
 
 // WeightedHeatImputer will make color of parent to weighted sum of colors of its children.
+// Raw heat is imputed same way from raw heat of children.
 type WeightedHeatImputer struct {
 	EmptyLeafHeat float64
 }
@@ -11,6 +12,7 @@ func (s WeightedHeatImputer) ImputeHeat(t Tree) {
 
 func (s WeightedHeatImputer) ImputeHeatNode(t Tree, node string) {
 	var heats []float64
+	var rawHeats []float64
 	var sizes []float64
 
 	for _, child := range t.To[node] {
@@ -19,30 +21,32 @@ func (s WeightedHeatImputer) ImputeHeatNode(t Tree, node string) {
 		if t.Nodes[child].HasHeat {
 			sizes = append(sizes, t.Nodes[child].Size)
 			heats = append(heats, t.Nodes[child].Heat)
+			rawHeats = append(rawHeats, t.Nodes[child].RawHeat)
 		}
 	}
 
 	if n, ok := t.Nodes[node]; !ok || !n.HasHeat {
-		v := s.EmptyLeafHeat
-		if len(t.To[node]) > 0 {
-			var totalSize float64
-			for _, childSize := range sizes {
-				totalSize += childSize
-			}
+		v, raw := s.EmptyLeafHeat, s.EmptyLeafHeat
+		var totalSize float64
+		for _, childSize := range sizes {
+			totalSize += childSize
+		}
 
-			v = 0.0
+		// children without sizes would make division by zero
+		if len(t.To[node]) > 0 && totalSize > 0 {
+			v, raw = 0.0, 0.0
 			for i := range sizes {
 				v += heats[i] * sizes[i]
+				raw += rawHeats[i] * sizes[i]
 			}
 			v /= totalSize
+			raw /= totalSize
 		}
 
-		t.Nodes[node] = Node{
-			Path:    t.Nodes[node].Path,
-			Name:    t.Nodes[node].Name,
-			Size:    t.Nodes[node].Size,
-			Heat:    v,
-			HasHeat: true,
-		}
+		n.Heat = v
+		n.RawHeat = raw
+		n.HasRawHeat = true
+		n.HasHeat = true
+		t.Nodes[node] = n
 	}
 }
@@ -0,0 +1,31 @@
+package treemap
+
+import "testing"
+
+func TestWeightedHeatImputer(t *testing.T) {
+	tree := Tree{
+		Nodes: map[string]Node{
+			"a":   {Path: "a", Name: "a", Size: 4, Attributes: map[string]string{"owner": "x"}},
+			"a/b": {Path: "a/b", Size: 3, Heat: 1, HasHeat: true, RawHeat: 10, HasRawHeat: true},
+			"a/c": {Path: "a/c", Size: 1, Heat: 0, HasHeat: true, RawHeat: 50, HasRawHeat: true},
+			"a/d": {Path: "a/d", Size: 1},
+		},
+		To: map[string][]string{
+			"a": {"a/b", "a/c", "a/d"},
+		},
+		Root: "a",
+	}
+
+	WeightedHeatImputer{EmptyLeafHeat: 0.5}.ImputeHeat(tree)
+
+	// a/d gets empty leaf heat, then a is weighted by size
+	if n := tree.Nodes["a/d"]; !n.HasHeat || n.Heat != 0.5 || n.RawHeat != 0.5 {
+		t.Errorf("leaf: got(%#v)", n)
+	}
+	if n := tree.Nodes["a"]; n.Heat != 3.5/5 || n.RawHeat != 80.5/5 {
+		t.Errorf("parent: heat(%v) raw(%v)", n.Heat, n.RawHeat)
+	}
+	if n := tree.Nodes["a"]; n.Name != "a" || n.Attributes["owner"] != "x" {
+		t.Errorf("parent fields are not kept: got(%#v)", n)
+	}
+}